@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/packages"
+)
+
+// Diagnostic is a single finding produced by a go/analysis.Analyzer, folded
+// into the exported graph alongside Entities/Relationships.
+type Diagnostic struct {
+	Analyzer string `json:"analyzer"`
+	Category string `json:"category,omitempty"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// builtinAnalyzers is the registry -analyzers=name,name,... selects from.
+// Additional entries (e.g. staticcheck's SA/S/ST checks, or a compiled-in
+// house rule) can be added with RegisterAnalyzer; the request's "plugin
+// package" route is also just a call to RegisterAnalyzer after
+// plugin.Open+Lookup resolves an *analysis.Analyzer symbol.
+var builtinAnalyzers = map[string]*analysis.Analyzer{
+	"nilness": nilness.Analyzer,
+	"printf":  printf.Analyzer,
+	"shadow":  shadow.Analyzer,
+}
+
+// RegisterAnalyzer adds an analyzer to the registry under name.
+func RegisterAnalyzer(name string, a *analysis.Analyzer) {
+	builtinAnalyzers[name] = a
+}
+
+// ResolveAnalyzers looks up each requested analyzer name in the registry.
+func ResolveAnalyzers(names []string) ([]*analysis.Analyzer, error) {
+	var analyzers []*analysis.Analyzer
+	for _, name := range names {
+		a, ok := builtinAnalyzers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown analyzer %q (known: nilness, printf, shadow, or any RegisterAnalyzer-ed plugin)", name)
+		}
+		analyzers = append(analyzers, a)
+	}
+	return analyzers, nil
+}
+
+// AnalysisDriver runs a DAG of go/analysis.Analyzer values over a set of
+// loaded packages, modeled after gopls's modular analysis driver: packages
+// are visited in import postorder (dependencies before dependents), and
+// within each package analyzers run in an order satisfying their Requires
+// edges. Facts are propagated via ImportObjectFact/ImportPackageFact from
+// already-analyzed packages, and are round-tripped through encoding/gob per
+// package to keep the cache boundary honest (a later incremental cache can
+// persist exactly these bytes to disk).
+type AnalysisDriver struct {
+	fset      *token.FileSet
+	analyzers []*analysis.Analyzer
+
+	objectFacts  map[types.Object]map[string]analysis.Fact
+	packageFacts map[*types.Package]map[string]analysis.Fact
+	factCache    map[string][]byte // pkgPath -> gob-encoded facts exported while analyzing it
+}
+
+// NewAnalysisDriver builds a driver for the given analyzers, topologically
+// sorted so each analyzer's Requires have already run by the time it does.
+func NewAnalysisDriver(fset *token.FileSet, analyzers []*analysis.Analyzer) *AnalysisDriver {
+	return &AnalysisDriver{
+		fset:         fset,
+		analyzers:    sortAnalyzersByRequires(analyzers),
+		objectFacts:  make(map[types.Object]map[string]analysis.Fact),
+		packageFacts: make(map[*types.Package]map[string]analysis.Fact),
+		factCache:    make(map[string][]byte),
+	}
+}
+
+// sortAnalyzersByRequires returns analyzers in dependency (postorder) order.
+func sortAnalyzersByRequires(analyzers []*analysis.Analyzer) []*analysis.Analyzer {
+	visited := make(map[*analysis.Analyzer]bool)
+	var order []*analysis.Analyzer
+	var visit func(a *analysis.Analyzer)
+	visit = func(a *analysis.Analyzer) {
+		if visited[a] {
+			return
+		}
+		visited[a] = true
+		for _, req := range a.Requires {
+			visit(req)
+		}
+		order = append(order, a)
+	}
+	for _, a := range analyzers {
+		visit(a)
+	}
+	return order
+}
+
+// Run walks pkgs in import postorder and runs every analyzer on every
+// package, returning all reported diagnostics plus a map from diagnostic
+// index to the *types.Object-less position it was reported at (used by the
+// caller to attach has_diagnostic relationships).
+func (d *AnalysisDriver) Run(pkgs []*packages.Package) []Diagnostic {
+	var diagnostics []Diagnostic
+
+	visited := make(map[string]bool)
+	var visitPkg func(pkg *packages.Package)
+	visitPkg = func(pkg *packages.Package) {
+		if pkg == nil || visited[pkg.PkgPath] {
+			return
+		}
+		visited[pkg.PkgPath] = true
+		for _, imp := range pkg.Imports {
+			visitPkg(imp)
+		}
+		if len(pkg.Errors) > 0 || pkg.Types == nil {
+			return
+		}
+		diagnostics = append(diagnostics, d.runPackage(pkg)...)
+	}
+
+	for _, pkg := range pkgs {
+		visitPkg(pkg)
+	}
+	return diagnostics
+}
+
+// runPackage runs every configured analyzer over a single package in
+// dependency order, giving each Pass the ResultOf of its Requires.
+func (d *AnalysisDriver) runPackage(pkg *packages.Package) []Diagnostic {
+	var diagnostics []Diagnostic
+	results := make(map[*analysis.Analyzer]interface{})
+
+	for _, a := range d.analyzers {
+		resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+		for _, req := range a.Requires {
+			resultOf[req] = results[req]
+		}
+
+		pass := &analysis.Pass{
+			Analyzer:   a,
+			Fset:       d.fset,
+			Files:      pkg.Syntax,
+			Pkg:        pkg.Types,
+			TypesInfo:  pkg.TypesInfo,
+			TypesSizes: pkg.TypesSizes,
+			ResultOf:   resultOf,
+			Report: func(diag analysis.Diagnostic) {
+				pos := d.fset.Position(diag.Pos)
+				diagnostics = append(diagnostics, Diagnostic{
+					Analyzer: a.Name,
+					Category: diag.Category,
+					Message:  diag.Message,
+					File:     pos.Filename,
+					Line:     pos.Line,
+					Column:   pos.Column,
+				})
+			},
+			ImportObjectFact:  d.importObjectFact,
+			ExportObjectFact:  d.exportObjectFact,
+			ImportPackageFact: d.importPackageFact(pkg.Types),
+			ExportPackageFact: d.exportPackageFact(pkg.Types),
+			AllObjectFacts:    d.allObjectFacts,
+			AllPackageFacts:   d.allPackageFacts,
+		}
+
+		result, err := a.Run(pass)
+		if err != nil {
+			continue // a single analyzer failing shouldn't abort the whole package
+		}
+		results[a] = result
+	}
+
+	d.cachePackageFacts(pkg)
+	return diagnostics
+}
+
+func factTypeName(fact analysis.Fact) string {
+	return reflect.TypeOf(fact).String()
+}
+
+func (d *AnalysisDriver) importObjectFact(obj types.Object, fact analysis.Fact) bool {
+	facts, ok := d.objectFacts[obj]
+	if !ok {
+		return false
+	}
+	stored, ok := facts[factTypeName(fact)]
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
+
+func (d *AnalysisDriver) exportObjectFact(obj types.Object, fact analysis.Fact) {
+	if d.objectFacts[obj] == nil {
+		d.objectFacts[obj] = make(map[string]analysis.Fact)
+	}
+	d.objectFacts[obj][factTypeName(fact)] = fact
+}
+
+func (d *AnalysisDriver) importPackageFact(pkg *types.Package) func(*types.Package, analysis.Fact) bool {
+	return func(p *types.Package, fact analysis.Fact) bool {
+		facts, ok := d.packageFacts[p]
+		if !ok {
+			return false
+		}
+		stored, ok := facts[factTypeName(fact)]
+		if !ok {
+			return false
+		}
+		reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+		return true
+	}
+}
+
+func (d *AnalysisDriver) exportPackageFact(pkg *types.Package) func(analysis.Fact) {
+	return func(fact analysis.Fact) {
+		if d.packageFacts[pkg] == nil {
+			d.packageFacts[pkg] = make(map[string]analysis.Fact)
+		}
+		d.packageFacts[pkg][factTypeName(fact)] = fact
+	}
+}
+
+func (d *AnalysisDriver) allObjectFacts() []analysis.ObjectFact {
+	var all []analysis.ObjectFact
+	for obj, facts := range d.objectFacts {
+		for _, fact := range facts {
+			all = append(all, analysis.ObjectFact{Object: obj, Fact: fact})
+		}
+	}
+	return all
+}
+
+func (d *AnalysisDriver) allPackageFacts() []analysis.PackageFact {
+	var all []analysis.PackageFact
+	for pkg, facts := range d.packageFacts {
+		for _, fact := range facts {
+			all = append(all, analysis.PackageFact{Package: pkg, Fact: fact})
+		}
+	}
+	return all
+}
+
+// diagnosticRelationships emits a has_diagnostic Relationship from every
+// Entity whose source range contains a diagnostic's position.
+func diagnosticRelationships(entities []Entity, diagnostics []Diagnostic, counter *int) []Relationship {
+	var relationships []Relationship
+	for _, diag := range diagnostics {
+		for _, e := range entities {
+			if e.File != diag.File || diag.Line < e.StartLine || diag.Line > e.EndLine {
+				continue
+			}
+			*counter++
+			relationships = append(relationships, Relationship{
+				ID:         fmt.Sprintf("rel_%d", *counter),
+				SourceID:   e.ID,
+				SourceName: e.Name,
+				TargetID:   "",
+				TargetName: diag.Analyzer,
+				Type:       "has_diagnostic",
+				Line:       diag.Line,
+				Metadata: map[string]string{
+					"analyzer": diag.Analyzer,
+					"message":  diag.Message,
+				},
+			})
+		}
+	}
+	return relationships
+}
+
+// cachePackageFacts gob-encodes every package-level fact exported while
+// analyzing pkg, keyed by pkg.PkgPath. This round-trips through gob (rather
+// than just keeping the live values) so the boundary matches exactly what a
+// persistent, cross-run cache would store.
+func (d *AnalysisDriver) cachePackageFacts(pkg *packages.Package) {
+	facts := d.packageFacts[pkg.Types]
+	if len(facts) == 0 {
+		return
+	}
+
+	values := make([]analysis.Fact, 0, len(facts))
+	for _, f := range facts {
+		values = append(values, f)
+	}
+
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(&values); err != nil {
+		return // fact type wasn't gob.Register-ed by its analyzer; skip caching it
+	}
+	d.factCache[pkg.PkgPath] = buf.Bytes()
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/packages"
+)
+
+// TestResolveAnalyzersKnownNames covers looking up the builtin registry.
+func TestResolveAnalyzersKnownNames(t *testing.T) {
+	analyzers, err := ResolveAnalyzers([]string{"nilness", "printf"})
+	if err != nil {
+		t.Fatalf("ResolveAnalyzers: %v", err)
+	}
+	if len(analyzers) != 2 {
+		t.Fatalf("expected 2 analyzers, got %d", len(analyzers))
+	}
+}
+
+// TestResolveAnalyzersUnknownName covers the error path for a name not in
+// builtinAnalyzers.
+func TestResolveAnalyzersUnknownName(t *testing.T) {
+	if _, err := ResolveAnalyzers([]string{"not-a-real-analyzer"}); err == nil {
+		t.Fatal("expected an error for an unknown analyzer name, got nil")
+	}
+}
+
+// TestSortAnalyzersByRequiresOrdersDependenciesFirst covers the dependency
+// topological sort: an analyzer appears only after everything in its
+// Requires chain.
+func TestSortAnalyzersByRequiresOrdersDependenciesFirst(t *testing.T) {
+	base := &analysis.Analyzer{Name: "base", Doc: "base", Run: func(*analysis.Pass) (interface{}, error) { return nil, nil }}
+	dependent := &analysis.Analyzer{Name: "dependent", Doc: "dependent", Requires: []*analysis.Analyzer{base}, Run: func(*analysis.Pass) (interface{}, error) { return nil, nil }}
+
+	order := sortAnalyzersByRequires([]*analysis.Analyzer{dependent})
+
+	baseIdx, dependentIdx := -1, -1
+	for i, a := range order {
+		if a == base {
+			baseIdx = i
+		}
+		if a == dependent {
+			dependentIdx = i
+		}
+	}
+	if baseIdx == -1 || dependentIdx == -1 {
+		t.Fatalf("expected both base and dependent in sorted order, got %v", order)
+	}
+	if baseIdx >= dependentIdx {
+		t.Errorf("base (idx %d) should come before dependent (idx %d)", baseIdx, dependentIdx)
+	}
+}
+
+// TestAnalysisDriverRunFindsPrintfDiagnostic runs the real printf analyzer
+// end to end over a package with a format-string/argument mismatch.
+func TestAnalysisDriverRunFindsPrintfDiagnostic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	src := `package p
+
+import "fmt"
+
+func F() {
+	fmt.Printf("%d\n", "not an int")
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			t.Fatalf("package load error: %v", e)
+		}
+	}
+
+	diagnostics := NewAnalysisDriver(fset, []*analysis.Analyzer{printf.Analyzer}).Run(pkgs)
+
+	var found bool
+	for _, d := range diagnostics {
+		if d.Analyzer == "printf" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a printf diagnostic for the %%d/string mismatch, got %+v", diagnostics)
+	}
+}
+
+// TestDiagnosticRelationshipsMatchesEntityRange covers diagnosticRelationships:
+// a diagnostic inside an entity's line range produces a has_diagnostic edge;
+// one outside it doesn't.
+func TestDiagnosticRelationshipsMatchesEntityRange(t *testing.T) {
+	entities := []Entity{
+		{ID: "e1", Name: "F", File: "p.go", StartLine: 5, EndLine: 10},
+	}
+	diagnostics := []Diagnostic{
+		{Analyzer: "nilness", Message: "in range", File: "p.go", Line: 7},
+		{Analyzer: "nilness", Message: "out of range", File: "p.go", Line: 20},
+		{Analyzer: "nilness", Message: "wrong file", File: "other.go", Line: 7},
+	}
+
+	counter := 0
+	rels := diagnosticRelationships(entities, diagnostics, &counter)
+
+	if len(rels) != 1 {
+		t.Fatalf("expected exactly 1 has_diagnostic relationship, got %d: %+v", len(rels), rels)
+	}
+	if rels[0].SourceID != "e1" || rels[0].Metadata["message"] != "in range" {
+		t.Errorf("unexpected relationship: %+v", rels[0])
+	}
+}
+
+func TestFactTypeNameUsesConcreteType(t *testing.T) {
+	var f analysis.Fact = &testFact{}
+	if got, want := factTypeName(f), reflect.TypeOf(f).String(); got != want {
+		t.Errorf("factTypeName = %q, want %q", got, want)
+	}
+}
+
+type testFact struct{}
+
+func (*testFact) AFact()         {}
+func (*testFact) String() string { return "testFact" }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/rta"
+	"golang.org/x/tools/go/callgraph/static"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// callGraphAlgorithms are the selectable -callgraph values.
+const (
+	CallGraphCHA    = "cha"
+	CallGraphRTA    = "rta"
+	CallGraphVTA    = "vta"
+	CallGraphStatic = "static"
+)
+
+// BuildSSACallGraph builds golang.org/x/tools/go/ssa for the loaded packages
+// and runs the requested callgraph algorithm, returning one Relationship per
+// resolved call edge with TargetID pointing at the callee's Entity.ID.
+//
+// Unlike extractFunctionCalls (which records the textual callee expression
+// with an empty TargetID), this resolves method dispatch through interfaces
+// and indirect/func-value calls wherever the chosen algorithm can.
+func BuildSSACallGraph(pkgs []*packages.Package, fset *token.FileSet, entities []Entity, algo string, counter *int) ([]Relationship, error) {
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+
+	index := newEntityPositionIndex(entities, fset)
+
+	var cg *callgraph.Graph
+	switch algo {
+	case CallGraphCHA:
+		cg = cha.CallGraph(prog)
+	case CallGraphStatic:
+		cg = static.CallGraph(prog)
+	case CallGraphRTA:
+		roots := rtaRoots(ssaPkgs)
+		cg = rta.Analyze(roots, true).CallGraph
+	case CallGraphVTA:
+		funcs := ssautil.AllFunctions(prog)
+		cg = vta.CallGraph(funcs, cha.CallGraph(prog))
+	default:
+		return nil, fmt.Errorf("unknown callgraph algorithm %q (want one of cha, rta, vta, static)", algo)
+	}
+
+	var relationships []Relationship
+	callgraph.GraphVisitEdges(cg, func(edge *callgraph.Edge) error {
+		if edge.Caller == nil || edge.Caller.Func == nil || edge.Callee == nil || edge.Callee.Func == nil {
+			return nil
+		}
+
+		sourceID, sourceOK := index.lookup(edge.Caller.Func)
+		targetID, targetOK := index.lookup(edge.Callee.Func)
+		if !sourceOK {
+			return nil // caller isn't one of our extracted entities (e.g. synthetic/stdlib wrapper)
+		}
+
+		*counter++
+		line := 0
+		if edge.Site != nil {
+			line = fset.Position(edge.Site.Pos()).Line
+		}
+
+		relationships = append(relationships, Relationship{
+			ID:         fmt.Sprintf("rel_%d", *counter),
+			SourceID:   sourceID,
+			SourceName: edge.Caller.Func.Name(),
+			TargetID:   targetID,
+			TargetName: edge.Callee.Func.Name(),
+			Type:       "calls",
+			Line:       line,
+			Metadata: map[string]string{
+				"call_kind":       callKind(edge),
+				"callgraph_algo":  algo,
+				"target_resolved": fmt.Sprintf("%t", targetOK),
+			},
+		})
+		return nil
+	})
+
+	return relationships, nil
+}
+
+// callKind classifies a callgraph edge the way the request asks: static
+// (direct call to a known function), interface (dispatch through an
+// interface method set) or dynamic (any other indirect call, e.g. through a
+// func value).
+func callKind(edge *callgraph.Edge) string {
+	if edge.Site == nil {
+		return "static"
+	}
+	common := edge.Site.Common()
+	if common.StaticCallee() != nil {
+		return "static"
+	}
+	if common.IsInvoke() {
+		return "interface"
+	}
+	return "dynamic"
+}
+
+// rtaRoots seeds RTA from main/init of every main package plus every
+// exported function of every package, mirroring how staticcheck-style
+// whole-program analyses pick conservative roots for libraries.
+func rtaRoots(ssaPkgs []*ssa.Package) []*ssa.Function {
+	var roots []*ssa.Function
+	mains := ssautil.MainPackages(ssaPkgs)
+	for _, m := range mains {
+		if fn := m.Func("main"); fn != nil {
+			roots = append(roots, fn)
+		}
+		if fn := m.Func("init"); fn != nil {
+			roots = append(roots, fn)
+		}
+	}
+	for _, pkg := range ssaPkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, member := range pkg.Members {
+			fn, ok := member.(*ssa.Function)
+			if !ok || !fn.Object().Exported() {
+				continue
+			}
+			roots = append(roots, fn)
+		}
+	}
+	return roots
+}
+
+// entityPositionIndex resolves an *ssa.Function back to the Entity.ID that
+// extractEntitiesFromFile assigned it, by matching pkgPath+name+line. This
+// deliberately avoids keying on the file path: Entity.File is stored
+// relative to repoPath (see analyzeGoRepository), but fset positions are
+// always absolute, so a file-based key never matches. It also avoids keying
+// on the short package name (e.g. annotateComplexity's package:name:line),
+// since two different packages can share a short name - pkgPath is unique.
+type entityPositionIndex struct {
+	fset  *token.FileSet
+	byKey map[string]string
+}
+
+func newEntityPositionIndex(entities []Entity, fset *token.FileSet) *entityPositionIndex {
+	idx := &entityPositionIndex{fset: fset, byKey: make(map[string]string, len(entities))}
+	for _, e := range entities {
+		if e.Type != "function" && e.Type != "method" {
+			continue
+		}
+		idx.byKey[fmt.Sprintf("%s:%s:%d", e.PkgPath, e.Name, e.StartLine)] = e.ID
+	}
+	return idx
+}
+
+func (idx *entityPositionIndex) lookup(fn *ssa.Function) (string, bool) {
+	if fn == nil || fn.Pos() == token.NoPos || fn.Pkg == nil {
+		return "", false
+	}
+	pos := idx.fset.Position(fn.Pos())
+	key := fmt.Sprintf("%s:%s:%d", fn.Pkg.Pkg.Path(), fn.Name(), pos.Line)
+	id, ok := idx.byKey[key]
+	return id, ok
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// entitiesForFuncs builds a minimal []Entity covering every top-level
+// function/method in pkgs, keyed the same way extractEntitiesFromFile does
+// (file:line:name from the FuncDecl's own position), so entityPositionIndex
+// can resolve an *ssa.Function back to it.
+func entitiesForFuncs(pkgs []*packages.Package, fset *token.FileSet) []Entity {
+	var entities []Entity
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				fn, ok := n.(*ast.FuncDecl)
+				if !ok {
+					return true
+				}
+				pos := fset.Position(fn.Pos())
+				entities = append(entities, Entity{
+					ID:        pos.Filename + ":" + fn.Name.Name,
+					Name:      fn.Name.Name,
+					Type:      "function",
+					Package:   pkg.Name,
+					PkgPath:   pkg.PkgPath,
+					File:      pos.Filename,
+					StartLine: pos.Line,
+				})
+				return true
+			})
+		}
+	}
+	return entities
+}
+
+// TestBuildSSACallGraphCHAFindsDirectCall covers BuildSSACallGraph end to
+// end: a direct call from Caller to Callee must come back as a "calls"
+// relationship with a static call_kind, resolved to both entities' IDs.
+func TestBuildSSACallGraphCHAFindsDirectCall(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	src := `package main
+
+func Callee() int { return 1 }
+
+func Caller() int { return Callee() }
+
+func main() { Caller() }
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			t.Fatalf("package load error: %v", e)
+		}
+	}
+
+	entities := entitiesForFuncs(pkgs, fset)
+	counter := 0
+	rels, err := BuildSSACallGraph(pkgs, fset, entities, CallGraphCHA, &counter)
+	if err != nil {
+		t.Fatalf("BuildSSACallGraph: %v", err)
+	}
+
+	var found *Relationship
+	for i := range rels {
+		if rels[i].SourceName == "Caller" && rels[i].TargetName == "Callee" {
+			found = &rels[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("expected a calls edge from Caller to Callee, got %+v", rels)
+	}
+	if found.Metadata["call_kind"] != "static" {
+		t.Errorf("direct call should classify as static, got %q", found.Metadata["call_kind"])
+	}
+	if found.Metadata["target_resolved"] != "true" {
+		t.Errorf("Callee should resolve to its extracted entity, got target_resolved=%q", found.Metadata["target_resolved"])
+	}
+}
+
+// TestCallKindClassifiesStaticInterfaceAndDynamic covers callKind's three
+// buckets using a real callgraph built by CHA over direct, interface, and
+// func-value calls.
+func TestCallKindClassifiesStaticInterfaceAndDynamic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	src := `package main
+
+type Greeter interface{ Greet() string }
+type English struct{}
+
+func (English) Greet() string { return "hi" }
+
+func direct() { helper() }
+func helper() {}
+
+func viaInterface(g Greeter) string { return g.Greet() }
+
+func viaFuncValue() {
+	f := helper
+	f()
+}
+
+func main() {
+	direct()
+	viaInterface(English{})
+	viaFuncValue()
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			t.Fatalf("package load error: %v", e)
+		}
+	}
+
+	entities := entitiesForFuncs(pkgs, fset)
+	counter := 0
+	rels, err := BuildSSACallGraph(pkgs, fset, entities, CallGraphCHA, &counter)
+	if err != nil {
+		t.Fatalf("BuildSSACallGraph: %v", err)
+	}
+
+	kinds := make(map[string]string)
+	for _, r := range rels {
+		kinds[r.SourceName+"->"+r.TargetName] = r.Metadata["call_kind"]
+	}
+	if got := kinds["direct->helper"]; got != "static" {
+		t.Errorf("direct->helper: got call_kind=%q, want static", got)
+	}
+	if got := kinds["viaInterface->Greet"]; got != "interface" {
+		t.Errorf("viaInterface->Greet: got call_kind=%q, want interface", got)
+	}
+}
+
+// TestEntityPositionIndexDoesNotCollideAcrossSameNamePackages is a
+// regression test: two different packages sharing the short name "util"
+// each declare a same-named function on the same line, so a key built from
+// the short package name alone would collide and resolve a call to the
+// wrong package's entity. entityPositionIndex must key on the unique
+// import path instead.
+func TestEntityPositionIndexDoesNotCollideAcrossSameNamePackages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	for _, sub := range []string{"a/util", "b/util"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", sub, err)
+		}
+		src := "package util\n\nfunc Helper() {}\n"
+		if err := os.WriteFile(filepath.Join(dir, sub, "util.go"), []byte(src), 0644); err != nil {
+			t.Fatalf("write %s/util.go: %v", sub, err)
+		}
+	}
+	mainSrc := `package main
+
+import (
+	a "testpkg/a/util"
+	b "testpkg/b/util"
+)
+
+func main() {
+	a.Helper()
+	b.Helper()
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(mainSrc), 0644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			t.Fatalf("package load error: %v", e)
+		}
+	}
+
+	entities := entitiesForFuncs(pkgs, fset)
+	counter := 0
+	rels, err := BuildSSACallGraph(pkgs, fset, entities, CallGraphCHA, &counter)
+	if err != nil {
+		t.Fatalf("BuildSSACallGraph: %v", err)
+	}
+
+	targets := make(map[string]string) // caller package import path -> resolved target entity ID
+	for _, r := range rels {
+		if r.TargetName == "Helper" {
+			targets[r.SourceName] = r.TargetID
+		}
+	}
+
+	aTarget, aOK := targets["main"]
+	if !aOK {
+		t.Fatalf("expected a calls edge from main to a Helper, got rels %+v", rels)
+	}
+	_ = aTarget
+
+	byID := make(map[string]Entity, len(entities))
+	for _, e := range entities {
+		byID[e.ID] = e
+	}
+
+	var resolvedPkgPaths []string
+	for _, r := range rels {
+		if r.TargetName != "Helper" || r.TargetID == "" {
+			continue
+		}
+		e, ok := byID[r.TargetID]
+		if !ok {
+			t.Fatalf("relationship targets unknown entity ID %q", r.TargetID)
+		}
+		resolvedPkgPaths = append(resolvedPkgPaths, e.PkgPath)
+	}
+	if len(resolvedPkgPaths) != 2 {
+		t.Fatalf("expected both Helper calls to resolve to an entity, got %v", resolvedPkgPaths)
+	}
+	if resolvedPkgPaths[0] == resolvedPkgPaths[1] {
+		t.Errorf("both calls resolved to the same entity (%s) despite targeting distinct util packages - a.Helper and b.Helper collided", resolvedPkgPaths[0])
+	}
+}
@@ -0,0 +1,947 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// BasicBlockCFGAnalyzer builds a real basic-block control flow graph for each
+// function, in the style of golang.org/x/tools/go/cfg: every block owns a run
+// of straight-line statements plus a list of successor blocks. Branch, loop,
+// switch and select constructs produce real edges instead of the nodeID+1
+// linear chain the previous SimpleCFGAnalyzer produced.
+type BasicBlockCFGAnalyzer struct {
+	fileSet *token.FileSet
+	verbose bool
+
+	// enableSSA/ssaSanity control the optional pruned-SSA pass built on top
+	// of each function's CFG (see ssa_builder.go).
+	enableSSA bool
+	ssaSanity bool
+
+	// enableChecks controls the block-level static checks (see cfg_checks.go).
+	enableChecks bool
+
+	// cache/pkgKeys wire in the content-addressed per-package cache (see
+	// package_cache.go); cache is nil when the cache is disabled.
+	cache   *PackageCache
+	pkgKeys map[string]string
+}
+
+// NewBasicBlockCFGAnalyzer creates a new basic-block CFG analyzer.
+func NewBasicBlockCFGAnalyzer(fset *token.FileSet, verbose bool) *BasicBlockCFGAnalyzer {
+	return &BasicBlockCFGAnalyzer{
+		fileSet: fset,
+		verbose: verbose,
+	}
+}
+
+// AnalyzeControlFlow performs control flow analysis over every function in pkgs.
+func (bca *BasicBlockCFGAnalyzer) AnalyzeControlFlow(pkgs []*packages.Package) ControlFlowAnalysis {
+	var allCFGs []CFGResult
+
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			continue // Skip packages with errors
+		}
+
+		var cacheKey string
+		if bca.cache != nil {
+			cacheKey = cfgCacheKey(bca.pkgKeys[pkg.PkgPath])
+			if entry, hit := bca.cache.Load(cacheKey); hit {
+				allCFGs = append(allCFGs, entry.CFG...)
+				continue
+			}
+		}
+
+		var pkgCFGs []CFGResult
+		for _, file := range pkg.Syntax {
+			cfgs := bca.analyzeFunctions(file, pkg)
+			pkgCFGs = append(pkgCFGs, cfgs...)
+		}
+		allCFGs = append(allCFGs, pkgCFGs...)
+
+		if bca.cache != nil {
+			_ = bca.cache.Store(cacheKey, &PackageCacheEntry{CFG: pkgCFGs})
+		}
+	}
+
+	return ControlFlowAnalysis{
+		Functions: allCFGs,
+		Summary:   bca.calculateSummary(allCFGs),
+	}
+}
+
+// calculateSummary computes summary statistics for all CFG results.
+func (bca *BasicBlockCFGAnalyzer) calculateSummary(cfgs []CFGResult) struct {
+	TotalFunctions    int     `json:"total_functions"`
+	AverageComplexity float64 `json:"average_complexity"`
+	MaxComplexity     int     `json:"max_complexity"`
+	TotalUnreachable  int     `json:"total_unreachable_blocks"`
+} {
+	summary := struct {
+		TotalFunctions    int     `json:"total_functions"`
+		AverageComplexity float64 `json:"average_complexity"`
+		MaxComplexity     int     `json:"max_complexity"`
+		TotalUnreachable  int     `json:"total_unreachable_blocks"`
+	}{
+		TotalFunctions: len(cfgs),
+	}
+
+	if len(cfgs) == 0 {
+		return summary
+	}
+
+	totalComplexity := 0
+	maxComplexity := 0
+	totalUnreachable := 0
+
+	for _, cfg := range cfgs {
+		totalComplexity += cfg.CyclomaticComplexity
+		if cfg.CyclomaticComplexity > maxComplexity {
+			maxComplexity = cfg.CyclomaticComplexity
+		}
+		totalUnreachable += len(cfg.UnreachableBlocks)
+	}
+
+	summary.AverageComplexity = float64(totalComplexity) / float64(len(cfgs))
+	summary.MaxComplexity = maxComplexity
+	summary.TotalUnreachable = totalUnreachable
+
+	return summary
+}
+
+// analyzeFunctions extracts and analyzes all functions in a file.
+func (bca *BasicBlockCFGAnalyzer) analyzeFunctions(file *ast.File, pkg *packages.Package) []CFGResult {
+	var results []CFGResult
+
+	filename := bca.fileSet.Position(file.Pos()).Filename
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			if node.Body != nil { // Only analyze functions with bodies
+				cfgResult := bca.buildCFG(node, pkg, filename)
+				results = append(results, cfgResult)
+
+				if bca.verbose {
+					fmt.Printf("Generated CFG for function %s: %d blocks, %d edges, complexity %d\n",
+						cfgResult.FunctionName, len(cfgResult.Nodes), cfgResult.EdgeCount, cfgResult.CyclomaticComplexity)
+				}
+			}
+		}
+		return true
+	})
+
+	return results
+}
+
+// buildCFG builds the basic-block CFG for a single function.
+func (bca *BasicBlockCFGAnalyzer) buildCFG(fn *ast.FuncDecl, pkg *packages.Package, filename string) CFGResult {
+	functionID := fmt.Sprintf("func_%s_%d", fn.Name.Name, bca.fileSet.Position(fn.Pos()).Line)
+
+	builder := &cfgBuilder{
+		fileSet: bca.fileSet,
+		fn:      fn,
+		labels:  make(map[string]*cfgBlock),
+	}
+	builder.build()
+
+	nodes, edgeCount := builder.toNodes()
+	unreachable := builder.unreachableBlocks()
+
+	analyzer := &functionAnalyzer{fileSet: bca.fileSet, function: fn}
+	complexity := analyzer.countDecisionPoints(nodes, edgeCount)
+
+	result := CFGResult{
+		FunctionName:         fn.Name.Name,
+		FunctionID:           functionID,
+		Package:              pkg.Name,
+		File:                 filename,
+		Nodes:                nodes,
+		EdgeCount:            edgeCount,
+		CyclomaticComplexity: complexity,
+		UnreachableBlocks:    unreachable,
+	}
+
+	if bca.enableSSA {
+		ssa := NewSSABuilder(builder.blocks, builder.entryBlock.id, pkg, bca.ssaSanity).Build()
+		result.SSA = &ssa
+	}
+
+	if bca.enableChecks {
+		result.Findings = runCFGChecks(builder.blocks, fn, pkg, unreachable)
+	}
+
+	return result
+}
+
+// cfgBlock is a single basic block: a run of straight-line statements plus
+// the set of blocks control can transfer to next.
+type cfgBlock struct {
+	id    int
+	kind  string // entry, block, if, for, range, switch, typeswitch, select, case, return, panic, implicit_fallthrough, defer_exit, exit
+	label string // descriptive text for branch/header blocks
+	cond  ast.Expr // the condition expression for if/for header blocks, used by the constant-condition check
+	stmts []ast.Stmt
+	line  int
+	succs []*cfgBlock
+	preds []*cfgBlock
+}
+
+// loopCtx tracks an enclosing loop so break/continue (possibly labeled) can
+// be wired to the right block.
+type loopCtx struct {
+	label          string
+	continueTarget *cfgBlock
+	breakTarget    *cfgBlock
+}
+
+// switchCtx tracks an enclosing switch/select so break (possibly labeled)
+// resolves to the right join block.
+type switchCtx struct {
+	label       string
+	breakTarget *cfgBlock
+}
+
+// cfgBuilder walks a function body and materializes it into basic blocks.
+type cfgBuilder struct {
+	fileSet *token.FileSet
+	fn      *ast.FuncDecl
+
+	blocks  []*cfgBlock
+	current *cfgBlock
+
+	entryBlock *cfgBlock
+	exitBlock  *cfgBlock
+	deferExit  *cfgBlock // synthetic block holding deferred calls, reached by every return/panic
+	labels     map[string]*cfgBlock
+	loopStack  []loopCtx
+	switchStack []switchCtx
+
+	pendingLabel string // label attached to the next for/range/switch/select we build
+}
+
+func (b *cfgBuilder) newBlock(kind string) *cfgBlock {
+	blk := &cfgBlock{id: len(b.blocks), kind: kind}
+	b.blocks = append(b.blocks, blk)
+	return blk
+}
+
+func (b *cfgBuilder) link(from, to *cfgBlock) {
+	if from == nil || to == nil {
+		return
+	}
+	from.succs = append(from.succs, to)
+	to.preds = append(to.preds, from)
+}
+
+// build constructs the CFG for b.fn.
+func (b *cfgBuilder) build() {
+	b.exitBlock = b.newBlock("exit")
+	b.collectLabels(b.fn.Body)
+
+	entry := b.newBlock("entry")
+	entry.line = b.fileSet.Position(b.fn.Pos()).Line
+	b.entryBlock = entry
+	b.current = entry
+
+	b.stmtList(b.fn.Body.List)
+
+	// Falling off the end of the function body is an implicit return; mark
+	// the block distinctly (unless it's the untouched entry/header block of
+	// a construct) so checkMissingReturn can find it.
+	if b.current != nil {
+		if b.current.kind == "block" {
+			b.current.kind = "implicit_fallthrough"
+		}
+		b.link(b.current, b.terminalTarget())
+	}
+
+	if b.deferExit != nil {
+		b.link(b.deferExit, b.exitBlock)
+	}
+}
+
+// collectLabels pre-creates a block for every labeled statement so that
+// forward gotos can be wired before the label itself is reached.
+func (b *cfgBuilder) collectLabels(body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if ls, ok := n.(*ast.LabeledStmt); ok {
+			b.labels[ls.Label.Name] = b.newBlock("label")
+		}
+		return true
+	})
+}
+
+func (b *cfgBuilder) terminalTarget() *cfgBlock {
+	if b.deferExit != nil {
+		return b.deferExit
+	}
+	return b.exitBlock
+}
+
+func (b *cfgBuilder) appendStmt(s ast.Stmt) {
+	if b.current == nil {
+		b.current = b.newBlock("block")
+	}
+	if b.current.line == 0 {
+		b.current.line = b.fileSet.Position(s.Pos()).Line
+	}
+	b.current.stmts = append(b.current.stmts, s)
+}
+
+func (b *cfgBuilder) stmtList(stmts []ast.Stmt) {
+	for i, s := range stmts {
+		if b.current == nil {
+			// Code after a terminator (return/break/...) is unreachable but
+			// still needs a block so the reachability sweep can flag it.
+			b.current = b.newBlock("block")
+		}
+		if ls, ok := s.(*ast.BranchStmt); ok && ls.Tok == token.FALLTHROUGH {
+			// fallthrough is only legal as the final statement of a case
+			// clause; handled by switchStmt/typeSwitchStmt directly.
+			_ = i
+			continue
+		}
+		b.stmt(s)
+	}
+}
+
+func (b *cfgBuilder) stmt(s ast.Stmt) {
+	switch n := s.(type) {
+	case *ast.IfStmt:
+		b.ifStmt(n)
+	case *ast.ForStmt:
+		b.forStmt(n)
+	case *ast.RangeStmt:
+		b.rangeStmt(n)
+	case *ast.SwitchStmt:
+		b.switchStmt(n)
+	case *ast.TypeSwitchStmt:
+		b.typeSwitchStmt(n)
+	case *ast.SelectStmt:
+		b.selectStmt(n)
+	case *ast.ReturnStmt:
+		b.returnStmt(n)
+	case *ast.BranchStmt:
+		b.branchStmt(n)
+	case *ast.LabeledStmt:
+		b.labeledStmt(n)
+	case *ast.BlockStmt:
+		b.stmtList(n.List)
+	case *ast.DeferStmt:
+		b.deferStmt(n)
+	case *ast.ExprStmt:
+		b.appendStmt(n)
+		if isPanicCall(n.X) {
+			b.current.kind = "panic"
+			b.link(b.current, b.terminalTarget())
+			b.current = nil
+		}
+	default:
+		b.appendStmt(s)
+	}
+}
+
+func isPanicCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "panic"
+}
+
+// buildCondChain decomposes cond's top-level &&/|| operators into a chain of
+// single-condition decision blocks with real short-circuit edges, instead of
+// collapsing the whole expression into one block/edge pair. Each leaf
+// sub-expression gets its own block (so countDecisionPoints counts it as a
+// separate decision point, and checkConstantConditions can fold it
+// independently); trueTarget/falseTarget are the blocks reached once the
+// whole expression is known to be true/false. seed, if non-nil, is reused as
+// the first (leftmost) leaf block instead of allocating a new one, so
+// straight-line code already accumulated in the current block stays attached
+// to it. kind labels the leaf blocks ("if" or "for").
+func (b *cfgBuilder) buildCondChain(kind string, cond ast.Expr, trueTarget, falseTarget, seed *cfgBlock) *cfgBlock {
+	if be, ok := cond.(*ast.BinaryExpr); ok && (be.Op == token.LAND || be.Op == token.LOR) {
+		if be.Op == token.LAND {
+			rightEntry := b.buildCondChain(kind, be.Y, trueTarget, falseTarget, nil)
+			return b.buildCondChain(kind, be.X, rightEntry, falseTarget, seed)
+		}
+		rightEntry := b.buildCondChain(kind, be.Y, trueTarget, falseTarget, nil)
+		return b.buildCondChain(kind, be.X, trueTarget, rightEntry, seed)
+	}
+
+	blk := seed
+	if blk == nil {
+		blk = b.newBlock(kind)
+	}
+	blk.kind = kind
+	blk.label = fmt.Sprintf("%s %s", kind, exprString(cond))
+	blk.cond = cond
+	if blk.line == 0 {
+		blk.line = b.fileSet.Position(cond.Pos()).Line
+	}
+	b.link(blk, trueTarget)
+	b.link(blk, falseTarget)
+	return blk
+}
+
+func (b *cfgBuilder) ifStmt(n *ast.IfStmt) {
+	if n.Init != nil {
+		b.stmt(n.Init)
+	}
+
+	join := b.newBlock("block")
+	thenBlock := b.newBlock("block")
+
+	elseEntry := join
+	if n.Else != nil {
+		elseEntry = b.newBlock("block")
+	}
+
+	entry := b.buildCondChain("if", n.Cond, thenBlock, elseEntry, b.current)
+	if entry.line == 0 {
+		entry.line = b.fileSet.Position(n.Pos()).Line
+	}
+
+	b.current = thenBlock
+	b.stmtList(n.Body.List)
+	b.link(b.current, join)
+
+	if n.Else != nil {
+		b.current = elseEntry
+		b.stmt(n.Else)
+		b.link(b.current, join)
+	}
+
+	b.current = join
+}
+
+func (b *cfgBuilder) forStmt(n *ast.ForStmt) {
+	if n.Init != nil {
+		b.stmt(n.Init)
+	}
+
+	label := b.takeLabel()
+
+	exit := b.newBlock("block")
+	body := b.newBlock("block")
+
+	var header *cfgBlock
+	if n.Cond != nil {
+		header = b.buildCondChain("for", n.Cond, body, exit, nil)
+		if header.line == 0 {
+			header.line = b.fileSet.Position(n.Pos()).Line
+		}
+	} else {
+		header = b.newBlock("for")
+		header.label = "for"
+		header.line = b.fileSet.Position(n.Pos()).Line
+		b.link(header, body)
+		b.link(header, exit)
+	}
+	b.link(b.current, header)
+
+	continueTarget := header
+	if n.Post != nil {
+		post := b.newBlock("block")
+		continueTarget = post
+	}
+
+	b.loopStack = append(b.loopStack, loopCtx{label: label, continueTarget: continueTarget, breakTarget: exit})
+
+	b.current = body
+	b.stmtList(n.Body.List)
+	if n.Post != nil {
+		b.link(b.current, continueTarget)
+		b.current = continueTarget
+		b.stmt(n.Post)
+		b.link(b.current, header)
+	} else {
+		b.link(b.current, header)
+	}
+
+	b.loopStack = b.loopStack[:len(b.loopStack)-1]
+	b.current = exit
+}
+
+func (b *cfgBuilder) rangeStmt(n *ast.RangeStmt) {
+	label := b.takeLabel()
+
+	header := b.newBlock("range")
+	header.label = fmt.Sprintf("range %s", exprString(n.X))
+	header.line = b.fileSet.Position(n.Pos()).Line
+	b.link(b.current, header)
+
+	exit := b.newBlock("block")
+	body := b.newBlock("block")
+	b.link(header, body)
+	b.link(header, exit)
+
+	b.loopStack = append(b.loopStack, loopCtx{label: label, continueTarget: header, breakTarget: exit})
+
+	b.current = body
+	b.stmtList(n.Body.List)
+	b.link(b.current, header)
+
+	b.loopStack = b.loopStack[:len(b.loopStack)-1]
+	b.current = exit
+}
+
+func (b *cfgBuilder) switchStmt(n *ast.SwitchStmt) {
+	if n.Init != nil {
+		b.stmt(n.Init)
+	}
+	label := b.takeLabel()
+
+	cond := b.current
+	if cond == nil {
+		cond = b.newBlock("switch")
+	}
+	cond.kind = "switch"
+	cond.label = "switch"
+	if n.Tag != nil {
+		cond.label = fmt.Sprintf("switch %s", exprString(n.Tag))
+	}
+	if cond.line == 0 {
+		cond.line = b.fileSet.Position(n.Pos()).Line
+	}
+
+	join := b.newBlock("block")
+	b.switchStack = append(b.switchStack, switchCtx{label: label, breakTarget: join})
+
+	hasDefault := false
+	var caseBlocks []*cfgBlock
+	for _, clause := range n.Body.List {
+		cc := clause.(*ast.CaseClause)
+		if len(cc.List) == 0 {
+			hasDefault = true
+		}
+		cb := b.newBlock("case")
+		cb.label = caseLabel(cc.List)
+		cb.line = b.fileSet.Position(cc.Pos()).Line
+		b.link(cond, cb)
+		caseBlocks = append(caseBlocks, cb)
+	}
+
+	for i, clause := range n.Body.List {
+		cc := clause.(*ast.CaseClause)
+		b.current = caseBlocks[i]
+		falls := b.caseBody(cc.Body)
+		if falls {
+			if i+1 < len(caseBlocks) {
+				b.link(b.current, caseBlocks[i+1])
+			} else {
+				b.link(b.current, join)
+			}
+		} else {
+			b.link(b.current, join)
+		}
+	}
+
+	if !hasDefault {
+		b.link(cond, join)
+	}
+
+	b.switchStack = b.switchStack[:len(b.switchStack)-1]
+	b.current = join
+}
+
+func (b *cfgBuilder) typeSwitchStmt(n *ast.TypeSwitchStmt) {
+	if n.Init != nil {
+		b.stmt(n.Init)
+	}
+	label := b.takeLabel()
+
+	cond := b.current
+	if cond == nil {
+		cond = b.newBlock("typeswitch")
+	}
+	cond.kind = "typeswitch"
+	cond.label = "type switch"
+	if cond.line == 0 {
+		cond.line = b.fileSet.Position(n.Pos()).Line
+	}
+
+	join := b.newBlock("block")
+	b.switchStack = append(b.switchStack, switchCtx{label: label, breakTarget: join})
+
+	hasDefault := false
+	for _, clause := range n.Body.List {
+		cc := clause.(*ast.CaseClause)
+		if len(cc.List) == 0 {
+			hasDefault = true
+		}
+		cb := b.newBlock("case")
+		cb.label = caseLabel(cc.List)
+		cb.line = b.fileSet.Position(cc.Pos()).Line
+		b.link(cond, cb)
+		b.current = cb
+		b.stmtList(cc.Body)
+		b.link(b.current, join)
+	}
+
+	if !hasDefault {
+		b.link(cond, join)
+	}
+
+	b.switchStack = b.switchStack[:len(b.switchStack)-1]
+	b.current = join
+}
+
+func (b *cfgBuilder) selectStmt(n *ast.SelectStmt) {
+	label := b.takeLabel()
+
+	cond := b.current
+	if cond == nil {
+		cond = b.newBlock("select")
+	}
+	cond.kind = "select"
+	cond.label = "select"
+	if cond.line == 0 {
+		cond.line = b.fileSet.Position(n.Pos()).Line
+	}
+
+	join := b.newBlock("block")
+	b.switchStack = append(b.switchStack, switchCtx{label: label, breakTarget: join})
+
+	for _, clause := range n.Body.List {
+		cc := clause.(*ast.CommClause)
+		cb := b.newBlock("comm")
+		cb.label = commLabel(cc.Comm)
+		cb.line = b.fileSet.Position(cc.Pos()).Line
+		b.link(cond, cb)
+		b.current = cb
+		b.stmtList(cc.Body)
+		b.link(b.current, join)
+	}
+
+	b.switchStack = b.switchStack[:len(b.switchStack)-1]
+	b.current = join
+}
+
+// caseBody processes a case clause's statements, returning true if the
+// clause ends in an explicit fallthrough to the next case.
+func (b *cfgBuilder) caseBody(stmts []ast.Stmt) bool {
+	if len(stmts) > 0 {
+		if br, ok := stmts[len(stmts)-1].(*ast.BranchStmt); ok && br.Tok == token.FALLTHROUGH {
+			b.stmtList(stmts[:len(stmts)-1])
+			return true
+		}
+	}
+	b.stmtList(stmts)
+	return false
+}
+
+func (b *cfgBuilder) returnStmt(n *ast.ReturnStmt) {
+	b.appendStmt(n)
+	b.current.kind = "return"
+	b.link(b.current, b.terminalTarget())
+	b.current = nil
+}
+
+func (b *cfgBuilder) branchStmt(n *ast.BranchStmt) {
+	label := ""
+	if n.Label != nil {
+		label = n.Label.Name
+	}
+
+	switch n.Tok {
+	case token.BREAK:
+		if target := b.resolveBreak(label); target != nil {
+			b.link(b.current, target)
+		}
+		b.current = nil
+	case token.CONTINUE:
+		if target := b.resolveContinue(label); target != nil {
+			b.link(b.current, target)
+		}
+		b.current = nil
+	case token.GOTO:
+		if target, ok := b.labels[label]; ok {
+			b.link(b.current, target)
+		}
+		b.current = nil
+	case token.FALLTHROUGH:
+		// handled by caseBody; reaching here means a malformed/unsupported
+		// fallthrough position, treat as a no-op terminator.
+		b.current = nil
+	}
+}
+
+func (b *cfgBuilder) resolveBreak(label string) *cfgBlock {
+	if label != "" {
+		for i := len(b.switchStack) - 1; i >= 0; i-- {
+			if b.switchStack[i].label == label {
+				return b.switchStack[i].breakTarget
+			}
+		}
+		for i := len(b.loopStack) - 1; i >= 0; i-- {
+			if b.loopStack[i].label == label {
+				return b.loopStack[i].breakTarget
+			}
+		}
+		return nil
+	}
+	if len(b.switchStack) > 0 {
+		return b.switchStack[len(b.switchStack)-1].breakTarget
+	}
+	if len(b.loopStack) > 0 {
+		return b.loopStack[len(b.loopStack)-1].breakTarget
+	}
+	return nil
+}
+
+func (b *cfgBuilder) resolveContinue(label string) *cfgBlock {
+	if label != "" {
+		for i := len(b.loopStack) - 1; i >= 0; i-- {
+			if b.loopStack[i].label == label {
+				return b.loopStack[i].continueTarget
+			}
+		}
+		return nil
+	}
+	if len(b.loopStack) > 0 {
+		return b.loopStack[len(b.loopStack)-1].continueTarget
+	}
+	return nil
+}
+
+func (b *cfgBuilder) labeledStmt(n *ast.LabeledStmt) {
+	target := b.labels[n.Label.Name]
+	b.link(b.current, target)
+	b.current = target
+
+	switch n.Stmt.(type) {
+	case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		b.pendingLabel = n.Label.Name
+	}
+	b.stmt(n.Stmt)
+}
+
+func (b *cfgBuilder) takeLabel() string {
+	l := b.pendingLabel
+	b.pendingLabel = ""
+	return l
+}
+
+func (b *cfgBuilder) deferStmt(n *ast.DeferStmt) {
+	if b.deferExit == nil {
+		b.deferExit = b.newBlock("defer_exit")
+	}
+	// Defers run LIFO at function exit; prepend so earlier defers end up
+	// executing last in the materialized exit block.
+	b.deferExit.stmts = append([]ast.Stmt{n}, b.deferExit.stmts...)
+	b.appendStmt(n)
+}
+
+// toNodes converts the built blocks into the public CFGNode representation
+// and returns the total number of successor edges.
+func (b *cfgBuilder) toNodes() ([]CFGNode, int) {
+	nodes := make([]CFGNode, 0, len(b.blocks))
+	edgeCount := 0
+
+	for _, blk := range b.blocks {
+		succs := make([]int, 0, len(blk.succs))
+		for _, s := range blk.succs {
+			succs = append(succs, s.id)
+		}
+		preds := make([]int, 0, len(blk.preds))
+		for _, p := range blk.preds {
+			preds = append(preds, p.id)
+		}
+		edgeCount += len(succs)
+
+		nodes = append(nodes, CFGNode{
+			ID:           blk.id,
+			Kind:         blk.kind,
+			Statement:    blk.statement(),
+			Line:         blk.line,
+			Successors:   succs,
+			Predecessors: preds,
+		})
+	}
+
+	return nodes, edgeCount
+}
+
+// unreachableBlocks does a forward reachability sweep from b.entryBlock and
+// returns the ids of every block it never reaches. The exit block is
+// created before the entry block (see build), so the entry is never at a
+// fixed index - it must be tracked explicitly rather than assumed to be
+// b.blocks[0].
+func (b *cfgBuilder) unreachableBlocks() []int {
+	reached := make([]bool, len(b.blocks))
+	if len(b.blocks) == 0 || b.entryBlock == nil {
+		return []int{}
+	}
+
+	queue := []*cfgBlock{b.entryBlock}
+	reached[b.entryBlock.id] = true
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, s := range cur.succs {
+			if !reached[s.id] {
+				reached[s.id] = true
+				queue = append(queue, s)
+			}
+		}
+	}
+
+	unreachable := []int{}
+	for _, blk := range b.blocks {
+		if !reached[blk.id] {
+			unreachable = append(unreachable, blk.id)
+		}
+	}
+	return unreachable
+}
+
+func (blk *cfgBlock) statement() string {
+	if blk.label != "" {
+		return blk.label
+	}
+	if len(blk.stmts) == 0 {
+		return blk.kind
+	}
+	var parts []string
+	for _, s := range blk.stmts {
+		parts = append(parts, stmtString(s))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func caseLabel(exprs []ast.Expr) string {
+	if len(exprs) == 0 {
+		return "default"
+	}
+	var parts []string
+	for _, e := range exprs {
+		parts = append(parts, exprString(e))
+	}
+	return "case " + strings.Join(parts, ", ")
+}
+
+func commLabel(comm ast.Stmt) string {
+	if comm == nil {
+		return "default"
+	}
+	return stmtString(comm)
+}
+
+// stmtString renders a short human-readable summary of a statement, used for
+// the CFGNode.Statement field.
+func stmtString(stmt ast.Stmt) string {
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		return exprString(s.X)
+	case *ast.AssignStmt:
+		if len(s.Lhs) > 0 && len(s.Rhs) > 0 {
+			return fmt.Sprintf("%s %s %s", exprString(s.Lhs[0]), s.Tok.String(), exprString(s.Rhs[0]))
+		}
+		return "assignment"
+	case *ast.DeclStmt:
+		return "declaration"
+	case *ast.SendStmt:
+		return fmt.Sprintf("%s <- %s", exprString(s.Chan), exprString(s.Value))
+	case *ast.IncDecStmt:
+		return fmt.Sprintf("%s%s", exprString(s.X), s.Tok.String())
+	case *ast.GoStmt:
+		return fmt.Sprintf("go %s", exprString(s.Call))
+	case *ast.DeferStmt:
+		return fmt.Sprintf("defer %s", exprString(s.Call))
+	case *ast.ReturnStmt:
+		if len(s.Results) > 0 {
+			return fmt.Sprintf("return %s", exprString(s.Results[0]))
+		}
+		return "return"
+	default:
+		return fmt.Sprintf("%T", stmt)
+	}
+}
+
+// exprString renders a short human-readable summary of an expression.
+func exprString(node ast.Expr) string {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name
+	case *ast.BasicLit:
+		return n.Value
+	case *ast.BinaryExpr:
+		return fmt.Sprintf("%s %s %s", exprString(n.X), n.Op.String(), exprString(n.Y))
+	case *ast.UnaryExpr:
+		return fmt.Sprintf("%s%s", n.Op.String(), exprString(n.X))
+	case *ast.CallExpr:
+		return fmt.Sprintf("%s()", exprString(n.Fun))
+	case *ast.SelectorExpr:
+		return fmt.Sprintf("%s.%s", exprString(n.X), n.Sel.Name)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
+
+// functionAnalyzer derives complexity metrics from a built CFG.
+type functionAnalyzer struct {
+	fileSet  *token.FileSet
+	function *ast.FuncDecl
+}
+
+// countDecisionPoints computes cyclomatic complexity from the real CFG as
+// edges - nodes + 2*connectedComponents (a single function body is one
+// connected component, but a defer_exit/exit pair reached only via panics
+// can leave extra components, so this is computed rather than assumed).
+func (fa *functionAnalyzer) countDecisionPoints(nodes []CFGNode, edgeCount int) int {
+	if len(nodes) == 0 {
+		return 1
+	}
+	components := countComponents(nodes)
+	complexity := edgeCount - len(nodes) + 2*components
+	if complexity < 1 {
+		complexity = 1
+	}
+	return complexity
+}
+
+// countComponents treats the CFG as undirected and counts connected
+// components via union-find over successor/predecessor edges.
+func countComponents(nodes []CFGNode) int {
+	parent := make([]int, len(nodes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		for parent[x] != x {
+			parent[x] = parent[parent[x]]
+			x = parent[x]
+		}
+		return x
+	}
+	union := func(a, b int) {
+		ra, rb := find(a), find(b)
+		if ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	for _, n := range nodes {
+		for _, s := range n.Successors {
+			union(n.ID, s)
+		}
+	}
+
+	roots := make(map[int]bool)
+	for i := range nodes {
+		roots[find(i)] = true
+	}
+	return len(roots)
+}
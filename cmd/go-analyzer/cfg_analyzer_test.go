@@ -0,0 +1,139 @@
+package main
+
+import "testing"
+
+// complexityOf builds the CFG for the named function in src and returns its
+// cyclomatic complexity, mirroring what buildCFG does without needing a
+// *packages.Package.
+func complexityOf(t *testing.T, src, name string) int {
+	t.Helper()
+	fn, fset := parseFunc(t, src, name)
+	builder := &cfgBuilder{fileSet: fset, fn: fn, labels: make(map[string]*cfgBlock)}
+	builder.build()
+	nodes, edgeCount := builder.toNodes()
+	analyzer := &functionAnalyzer{fileSet: fset, function: fn}
+	return analyzer.countDecisionPoints(nodes, edgeCount)
+}
+
+// TestIfStmtSplitsCompoundConditions is a regression test: a single "if"
+// block covering the whole n.Cond expression made `if a {}`, `if a && b {}`
+// and `if a && b && c {}` all produce identical complexity, since
+// countDecisionPoints derives its answer purely from edge/node counts.
+// buildCondChain must give each &&/|| operand its own block/edge pair so
+// additional operands are visible to the metric.
+func TestIfStmtSplitsCompoundConditions(t *testing.T) {
+	plain := complexityOf(t, `package p
+func F(a bool) int {
+	if a {
+		return 1
+	}
+	return 2
+}
+`, "F")
+
+	two := complexityOf(t, `package p
+func F(a, b bool) int {
+	if a && b {
+		return 1
+	}
+	return 2
+}
+`, "F")
+
+	three := complexityOf(t, `package p
+func F(a, b, c bool) int {
+	if a && b && c {
+		return 1
+	}
+	return 2
+}
+`, "F")
+
+	if two <= plain {
+		t.Errorf("complexity(a && b) = %d, want greater than complexity(a) = %d", two, plain)
+	}
+	if three <= two {
+		t.Errorf("complexity(a && b && c) = %d, want greater than complexity(a && b) = %d", three, two)
+	}
+}
+
+// TestIfStmtMixedAndOrRespectsPrecedence covers the `a && b || c` case,
+// which go/parser parses as `(a && b) || c` per && binding tighter than ||;
+// buildCondChain must follow that AST shape rather than flattening operators
+// left to right, or the short-circuit wiring would be wrong.
+func TestIfStmtMixedAndOrRespectsPrecedence(t *testing.T) {
+	src := `package p
+func F(a, b, c bool) int {
+	if a && b || c {
+		return 1
+	}
+	return 2
+}
+`
+	fn, fset := parseFunc(t, src, "F")
+	builder := &cfgBuilder{fileSet: fset, fn: fn, labels: make(map[string]*cfgBlock)}
+	builder.build()
+
+	var leaves []*cfgBlock
+	for _, blk := range builder.blocks {
+		if blk.kind == "if" {
+			leaves = append(leaves, blk)
+		}
+	}
+	if len(leaves) != 3 {
+		t.Fatalf("expected 3 leaf condition blocks for a && b || c, got %d", len(leaves))
+	}
+
+	var aBlock, bBlock, cBlock *cfgBlock
+	for _, blk := range leaves {
+		switch exprString(blk.cond) {
+		case "a":
+			aBlock = blk
+		case "b":
+			bBlock = blk
+		case "c":
+			cBlock = blk
+		}
+	}
+	if aBlock == nil || bBlock == nil || cBlock == nil {
+		t.Fatalf("expected leaf blocks for a, b and c, got %+v", leaves)
+	}
+
+	// a's false edge should short-circuit straight to c (the || fallback),
+	// not to b - that would wrongly require both a and c to be false.
+	if len(aBlock.succs) != 2 || aBlock.succs[1] != cBlock {
+		t.Errorf("a's false successor = %v, want c block (short-circuit to the || operand)", aBlock.succs)
+	}
+	// a's true edge should go to b, since a && b must still be evaluated.
+	if len(aBlock.succs) != 2 || aBlock.succs[0] != bBlock {
+		t.Errorf("a's true successor = %v, want b block", aBlock.succs)
+	}
+}
+
+// TestForStmtCondSplitsCompoundConditions covers forStmt's header the same
+// way TestIfStmtSplitsCompoundConditions covers ifStmt's.
+func TestForStmtCondSplitsCompoundConditions(t *testing.T) {
+	plain := complexityOf(t, `package p
+func F(a bool) int {
+	n := 0
+	for a {
+		n++
+	}
+	return n
+}
+`, "F")
+
+	compound := complexityOf(t, `package p
+func F(a, b bool) int {
+	n := 0
+	for a && b {
+		n++
+	}
+	return n
+}
+`, "F")
+
+	if compound <= plain {
+		t.Errorf("complexity(for a && b) = %d, want greater than complexity(for a) = %d", compound, plain)
+	}
+}
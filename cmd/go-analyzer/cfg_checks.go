@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// CFGFinding is a single block-level static-check result, in the spirit of
+// staticcheck's SA rules but derived directly from the CFG built in
+// cfg_analyzer.go.
+type CFGFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // "warning" or "error"
+	Block    int    `json:"block"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+// cfgChecker runs the block-level rules over a single function's CFG.
+type cfgChecker struct {
+	blocks []*cfgBlock
+	fn     *ast.FuncDecl
+	pkg    *packages.Package
+}
+
+func runCFGChecks(blocks []*cfgBlock, fn *ast.FuncDecl, pkg *packages.Package, unreachable []int) []CFGFinding {
+	c := &cfgChecker{blocks: blocks, fn: fn, pkg: pkg}
+
+	var findings []CFGFinding
+	findings = append(findings, c.checkUnreachable(unreachable)...)
+	findings = append(findings, c.checkMissingReturn()...)
+	findings = append(findings, c.checkConstantConditions()...)
+	findings = append(findings, c.checkUncheckedErrors()...)
+	return findings
+}
+
+func (c *cfgChecker) byID() map[int]*cfgBlock {
+	m := make(map[int]*cfgBlock, len(c.blocks))
+	for _, b := range c.blocks {
+		m[b.id] = b
+	}
+	return m
+}
+
+// checkUnreachable flags blocks the forward reachability sweep never
+// reaches and that carry real content - i.e. SA4006-style "code after an
+// unconditional return/panic is unreachable".
+func (c *cfgChecker) checkUnreachable(unreachable []int) []CFGFinding {
+	byID := c.byID()
+	var findings []CFGFinding
+	for _, id := range unreachable {
+		b := byID[id]
+		if b == nil || (len(b.stmts) == 0 && b.label == "") {
+			continue // trivial join/exit block, not real unreachable source
+		}
+		findings = append(findings, CFGFinding{
+			Rule:     "unreachable-code",
+			Severity: "warning",
+			Block:    id,
+			Line:     b.line,
+			Message:  fmt.Sprintf("unreachable code: block %d is never reached after an unconditional return/panic/branch", id),
+		})
+	}
+	return findings
+}
+
+// checkMissingReturn flags a non-void function that has a path falling off
+// the end of its body without an explicit return, panic or
+// runtime.Goexit. Functions whose only way out is an infinite loop with no
+// break are deliberately not flagged.
+//
+// This is a best-effort, CFG-local check: it cannot prove a loop never
+// exits, so an infinite `for {}` with no reachable break is treated the same
+// as a terminating loop; both leave the trailing fallthrough block
+// unreachable, which checkUnreachable already reports separately.
+func (c *cfgChecker) checkMissingReturn() []CFGFinding {
+	if !c.hasResults() {
+		return nil
+	}
+
+	var findings []CFGFinding
+	for _, b := range c.blocks {
+		if b.kind == "implicit_fallthrough" {
+			findings = append(findings, CFGFinding{
+				Rule:     "missing-return",
+				Severity: "error",
+				Block:    b.id,
+				Line:     b.line,
+				Message:  fmt.Sprintf("function %s declares return values but control can fall off the end without a return", c.fn.Name.Name),
+			})
+		}
+	}
+	return findings
+}
+
+func (c *cfgChecker) hasResults() bool {
+	if c.pkg.TypesInfo == nil {
+		return false
+	}
+	obj := c.pkg.TypesInfo.ObjectOf(c.fn.Name)
+	if obj == nil {
+		return false
+	}
+	sig, ok := obj.Type().(*types.Signature)
+	if !ok {
+		return false
+	}
+	return sig.Results() != nil && sig.Results().Len() > 0
+}
+
+// checkConstantConditions flags if/for conditions that go/types has already
+// evaluated to a compile-time constant boolean value.
+func (c *cfgChecker) checkConstantConditions() []CFGFinding {
+	if c.pkg.TypesInfo == nil {
+		return nil
+	}
+
+	var findings []CFGFinding
+	for _, b := range c.blocks {
+		if b.cond == nil {
+			continue
+		}
+		tv, ok := c.pkg.TypesInfo.Types[b.cond]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.Bool {
+			continue
+		}
+		value := constant.BoolVal(tv.Value)
+		findings = append(findings, CFGFinding{
+			Rule:     "constant-condition",
+			Severity: "warning",
+			Block:    b.id,
+			Line:     b.line,
+			Message:  fmt.Sprintf("condition is always %t: %s", value, b.label),
+		})
+	}
+	return findings
+}
+
+// checkUncheckedErrors is an intra-block heuristic: within a single basic
+// block, if an `error`-typed variable is assigned and then reassigned or the
+// block returns without an intervening read of that variable, flag it. This
+// does not track error variables across block boundaries, so it will miss
+// (and will not falsely flag) errors checked in a different block than
+// where they were assigned.
+func (c *cfgChecker) checkUncheckedErrors() []CFGFinding {
+	if c.pkg.TypesInfo == nil {
+		return nil
+	}
+
+	var findings []CFGFinding
+	for _, b := range c.blocks {
+		pending := map[string]int{} // error var name -> line of the unchecked assignment
+
+		for _, stmt := range b.stmts {
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok {
+				for name := range pending {
+					if usesIdentInStmt(stmt, name) {
+						delete(pending, name)
+					}
+				}
+				continue
+			}
+
+			for i, lhs := range assign.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok || id.Name == "_" || !c.isErrorType(id) {
+					continue
+				}
+				rhs := rhsFor(assign, i)
+				if usesIdent(rhs, id.Name) {
+					continue // e.g. `err = wrap(err)` reads err as part of the assignment
+				}
+				if line, stillPending := pending[id.Name]; stillPending {
+					findings = append(findings, CFGFinding{
+						Rule:     "unchecked-error",
+						Severity: "warning",
+						Block:    b.id,
+						Line:     line,
+						Message:  fmt.Sprintf("error variable %q reassigned before being checked", id.Name),
+					})
+				}
+				pending[id.Name] = b.line
+			}
+			for _, rhs := range assign.Rhs {
+				for name := range pending {
+					if usesIdent(rhs, name) {
+						delete(pending, name)
+					}
+				}
+			}
+		}
+
+		if len(pending) > 0 && (b.kind == "return" || b.kind == "implicit_fallthrough") {
+			for name, line := range pending {
+				findings = append(findings, CFGFinding{
+					Rule:     "unchecked-error",
+					Severity: "warning",
+					Block:    b.id,
+					Line:     line,
+					Message:  fmt.Sprintf("error variable %q assigned but never checked before the function returns", name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func rhsFor(s *ast.AssignStmt, lhsIndex int) ast.Expr {
+	if len(s.Rhs) == len(s.Lhs) {
+		return s.Rhs[lhsIndex]
+	}
+	if len(s.Rhs) == 1 {
+		return s.Rhs[0]
+	}
+	return nil
+}
+
+func usesIdent(expr ast.Expr, name string) bool {
+	if expr == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+func usesIdentInStmt(stmt ast.Stmt, name string) bool {
+	found := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name {
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+func (c *cfgChecker) isErrorType(id *ast.Ident) bool {
+	obj := c.pkg.TypesInfo.ObjectOf(id)
+	if obj == nil || obj.Type() == nil {
+		return false
+	}
+	return types.Implements(obj.Type(), errorInterface()) || obj.Type().String() == "error"
+}
+
+var cachedErrorInterface *types.Interface
+
+func errorInterface() *types.Interface {
+	if cachedErrorInterface != nil {
+		return cachedErrorInterface
+	}
+	method := types.NewFunc(token.NoPos, nil, "Error", types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.String])), false))
+	cachedErrorInterface = types.NewInterfaceType([]*types.Func{method}, nil).Complete()
+	return cachedErrorInterface
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadCFGCheckerPkg loads src as a single-file package and returns the
+// *packages.Package (with full type info) and its *token.FileSet.
+func loadCFGCheckerPkg(t *testing.T, src string) (*packages.Package, *token.FileSet) {
+	t.Helper()
+	return loadCFGCheckerPkgAllowErrors(t, src, false)
+}
+
+// loadCFGCheckerPkgAllowErrors is loadCFGCheckerPkg but tolerates type-check
+// errors. checkMissingReturn targets exactly the functions the compiler
+// itself already rejects as missing a return, so exercising it needs source
+// that go/types reports an error on - packages.Load still populates
+// TypesInfo for those, which is all cfgChecker needs.
+func loadCFGCheckerPkgAllowErrors(t *testing.T, src string, allowErrors bool) (*packages.Package, *token.FileSet) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	if !allowErrors {
+		for _, pkg := range pkgs {
+			for _, e := range pkg.Errors {
+				t.Fatalf("package load error: %v", e)
+			}
+		}
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("expected exactly one package, got %d", len(pkgs))
+	}
+	return pkgs[0], fset
+}
+
+// checksFor builds the CFG for the named function in pkg and runs
+// runCFGChecks over it.
+func checksFor(t *testing.T, pkg *packages.Package, fset *token.FileSet, name string) []CFGFinding {
+	t.Helper()
+	var fn *ast.FuncDecl
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if d, ok := decl.(*ast.FuncDecl); ok && d.Name.Name == name {
+				fn = d
+			}
+		}
+	}
+	if fn == nil {
+		t.Fatalf("function %s not found", name)
+	}
+
+	builder := &cfgBuilder{fileSet: fset, fn: fn, labels: make(map[string]*cfgBlock)}
+	builder.build()
+	unreachable := builder.unreachableBlocks()
+	return runCFGChecks(builder.blocks, fn, pkg, unreachable)
+}
+
+func findingsByRule(findings []CFGFinding, rule string) []CFGFinding {
+	var out []CFGFinding
+	for _, f := range findings {
+		if f.Rule == rule {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// TestCheckConstantConditionsFlagsAlwaysTrueLiteral covers the happy path:
+// go/types folds `true` directly, so the whole if-condition block carries a
+// constant bool value.
+func TestCheckConstantConditionsFlagsAlwaysTrueLiteral(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkg(t, `package p
+
+func F() int {
+	if true {
+		return 1
+	}
+	return 2
+}
+`)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "constant-condition")
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 constant-condition finding, got %d: %+v", len(findings), findings)
+	}
+}
+
+// TestCheckConstantConditionsSkipsNonConstant ensures an ordinary
+// variable-driven condition isn't flagged.
+func TestCheckConstantConditionsSkipsNonConstant(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkg(t, `package p
+
+func F(a bool) int {
+	if a {
+		return 1
+	}
+	return 2
+}
+`)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "constant-condition")
+	if len(findings) != 0 {
+		t.Fatalf("expected no constant-condition findings, got %+v", findings)
+	}
+}
+
+// TestCheckMissingReturnFlagsFallthrough covers a non-void function with a
+// path that falls off the end without an explicit return.
+func TestCheckMissingReturnFlagsFallthrough(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkgAllowErrors(t, `package p
+
+func F(a bool) int {
+	if a {
+		return 1
+	}
+}
+`, true)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "missing-return")
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 missing-return finding, got %d: %+v", len(findings), findings)
+	}
+}
+
+// TestCheckMissingReturnAllowsExhaustiveReturns ensures a function that
+// always returns on every path isn't flagged.
+func TestCheckMissingReturnAllowsExhaustiveReturns(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkg(t, `package p
+
+func F(a bool) int {
+	if a {
+		return 1
+	}
+	return 2
+}
+`)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "missing-return")
+	if len(findings) != 0 {
+		t.Fatalf("expected no missing-return findings, got %+v", findings)
+	}
+}
+
+// TestCheckUncheckedErrorsFlagsReassignmentBeforeCheck covers the
+// within-a-block heuristic: err is reassigned before the first one is ever
+// read.
+func TestCheckUncheckedErrorsFlagsReassignmentBeforeCheck(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkg(t, `package p
+
+func a() error { return nil }
+func b() error { return nil }
+
+func F() error {
+	err := a()
+	err = b()
+	return err
+}
+`)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "unchecked-error")
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 unchecked-error finding, got %d: %+v", len(findings), findings)
+	}
+}
+
+// TestCheckUncheckedErrorsAllowsCheckedAssignment ensures an error that is
+// read (e.g. compared against nil) before being reassigned isn't flagged.
+func TestCheckUncheckedErrorsAllowsCheckedAssignment(t *testing.T) {
+	pkg, fset := loadCFGCheckerPkg(t, `package p
+
+func a() error { return nil }
+func b() error { return nil }
+
+func F() error {
+	err := a()
+	if err != nil {
+		return err
+	}
+	err = b()
+	return err
+}
+`)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "unchecked-error")
+	if len(findings) != 0 {
+		t.Fatalf("expected no unchecked-error findings, got %+v", findings)
+	}
+}
+
+// TestCheckUnreachableFlagsCodeAfterReturn covers the classic case: a
+// statement placed after an unconditional return is dead.
+func TestCheckUnreachableFlagsCodeAfterReturn(t *testing.T) {
+	// go/types itself reports "missing return" here, since its
+	// terminating-statement rule only looks at a block's last statement and
+	// side() (not return) is last - packages.Load still populates TypesInfo
+	// despite the error, which is all cfgChecker needs.
+	pkg, fset := loadCFGCheckerPkgAllowErrors(t, `package p
+
+func side() {}
+
+func F() int {
+	return 1
+	side()
+}
+`, true)
+	findings := findingsByRule(checksFor(t, pkg, fset, "F"), "unreachable-code")
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 unreachable-code finding, got %d: %+v", len(findings), findings)
+	}
+}
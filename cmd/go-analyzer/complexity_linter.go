@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ComplexityFinding is a single cyclomatic-complexity lint violation, either
+// a function exceeding --max-complexity or a package exceeding
+// --package-average.
+type ComplexityFinding struct {
+	Kind              string  `json:"kind"` // "function" or "package"
+	File              string  `json:"file,omitempty"`
+	Line              int     `json:"line,omitempty"`
+	Function          string  `json:"function,omitempty"`
+	Package           string  `json:"package,omitempty"`
+	Complexity        int     `json:"complexity,omitempty"`
+	AverageComplexity float64 `json:"average_complexity,omitempty"`
+	Threshold         float64 `json:"threshold"`
+	Message           string  `json:"message"`
+}
+
+// ComplexityLintConfig configures a complexity lint pass.
+type ComplexityLintConfig struct {
+	MaxComplexity  int     // 0 disables the per-function check
+	PackageAverage float64 // 0 disables the per-package check
+	SkipTests      bool
+}
+
+// RunComplexityLint walks every function's CFGResult and emits a finding for
+// each function or package that crosses the configured thresholds.
+// Complexity counts &&/|| short-circuits and each case/comm-clause
+// individually, since buildCondChain gives every leaf operand its own CFG
+// block/edge pair and it is derived from the real CFG edge count.
+func RunComplexityLint(cfgs []CFGResult, cfg ComplexityLintConfig) []ComplexityFinding {
+	var findings []ComplexityFinding
+	packageComplexity := make(map[string][]int)
+
+	for _, fn := range cfgs {
+		if cfg.SkipTests && strings.HasSuffix(fn.File, "_test.go") {
+			continue
+		}
+
+		packageComplexity[fn.Package] = append(packageComplexity[fn.Package], fn.CyclomaticComplexity)
+
+		if cfg.MaxComplexity > 0 && fn.CyclomaticComplexity > cfg.MaxComplexity {
+			findings = append(findings, ComplexityFinding{
+				Kind:       "function",
+				File:       fn.File,
+				Line:       functionLine(fn),
+				Function:   fn.FunctionName,
+				Package:    fn.Package,
+				Complexity: fn.CyclomaticComplexity,
+				Threshold:  float64(cfg.MaxComplexity),
+				Message: fmt.Sprintf("function %s has cyclomatic complexity %d, exceeds max-complexity %d",
+					fn.FunctionName, fn.CyclomaticComplexity, cfg.MaxComplexity),
+			})
+		}
+	}
+
+	if cfg.PackageAverage > 0 {
+		var packages []string
+		for pkg := range packageComplexity {
+			packages = append(packages, pkg)
+		}
+		sort.Strings(packages)
+
+		for _, pkg := range packages {
+			avg := averageInt(packageComplexity[pkg])
+			if avg > cfg.PackageAverage {
+				findings = append(findings, ComplexityFinding{
+					Kind:              "package",
+					Package:           pkg,
+					AverageComplexity: avg,
+					Threshold:         cfg.PackageAverage,
+					Message: fmt.Sprintf("package %s has average complexity %.2f, exceeds package-average %.2f",
+						pkg, avg, cfg.PackageAverage),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings
+}
+
+func functionLine(fn CFGResult) int {
+	if len(fn.Nodes) > 0 {
+		return fn.Nodes[0].Line
+	}
+	return 0
+}
+
+func averageInt(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return float64(total) / float64(len(values))
+}
+
+// writeComplexityReport writes findings as both JSON and SARIF to
+// <basePath>.json and <basePath>.sarif.
+func writeComplexityReport(basePath string, findings []ComplexityFinding) error {
+	jsonData, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal complexity findings: %w", err)
+	}
+	if err := os.WriteFile(basePath+".json", jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write complexity JSON report: %w", err)
+	}
+
+	sarifData, err := json.MarshalIndent(complexityFindingsToSARIF(findings), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal complexity SARIF: %w", err)
+	}
+	if err := os.WriteFile(basePath+".sarif", sarifData, 0644); err != nil {
+		return fmt.Errorf("failed to write complexity SARIF report: %w", err)
+	}
+
+	return nil
+}
+
+// SARIF 2.1.0 types, kept minimal: just enough to drive CI annotations.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []sarifResult  `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine,omitempty"`
+}
+
+func complexityFindingsToSARIF(findings []ComplexityFinding) sarifLog {
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		ruleID := "complexity/max-complexity"
+		if f.Kind == "package" {
+			ruleID = "complexity/package-average"
+		}
+
+		result := sarifResult{
+			RuleID:  ruleID,
+			Level:   "warning",
+			Message: sarifMessage{Text: f.Message},
+		}
+		if f.File != "" {
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: f.File},
+					Region:           &sarifRegion{StartLine: f.Line},
+				},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	return sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "go-analyzer-complexity-linter",
+				InformationURI: "https://pkg.go.dev/go/ast",
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// annotateComplexity writes each function/method CFG's complexity score
+// onto the matching Entity's metadata so the graph output can style or
+// filter nodes by complexity.
+func annotateComplexity(entities []Entity, cfgs []CFGResult) {
+	byLocation := make(map[string]int, len(cfgs))
+	for _, fn := range cfgs {
+		key := fmt.Sprintf("%s:%s:%d", fn.Package, fn.FunctionName, functionLine(fn))
+		byLocation[key] = fn.CyclomaticComplexity
+	}
+
+	for i := range entities {
+		e := &entities[i]
+		if e.Type != "function" && e.Type != "method" {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s:%d", e.Package, e.Name, e.StartLine)
+		if complexity, ok := byLocation[key]; ok {
+			if e.Metadata == nil {
+				e.Metadata = make(map[string]string)
+			}
+			e.Metadata["complexity"] = fmt.Sprintf("%d", complexity)
+		}
+	}
+}
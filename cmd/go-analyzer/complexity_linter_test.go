@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunComplexityLintFlagsFunctionOverThreshold covers the --max-complexity
+// per-function check.
+func TestRunComplexityLintFlagsFunctionOverThreshold(t *testing.T) {
+	cfgs := []CFGResult{
+		{FunctionName: "F", Package: "p", File: "p.go", CyclomaticComplexity: 5},
+		{FunctionName: "G", Package: "p", File: "p.go", CyclomaticComplexity: 2},
+	}
+	findings := RunComplexityLint(cfgs, ComplexityLintConfig{MaxComplexity: 3})
+
+	var functionFindings []ComplexityFinding
+	for _, f := range findings {
+		if f.Kind == "function" {
+			functionFindings = append(functionFindings, f)
+		}
+	}
+	if len(functionFindings) != 1 || functionFindings[0].Function != "F" {
+		t.Fatalf("expected exactly one finding for F, got %+v", functionFindings)
+	}
+}
+
+// TestRunComplexityLintFlagsPackageAverage covers the --package-average
+// per-package check, independent of any single function's complexity.
+func TestRunComplexityLintFlagsPackageAverage(t *testing.T) {
+	cfgs := []CFGResult{
+		{FunctionName: "F", Package: "p", File: "p.go", CyclomaticComplexity: 4},
+		{FunctionName: "G", Package: "p", File: "p.go", CyclomaticComplexity: 4},
+	}
+	findings := RunComplexityLint(cfgs, ComplexityLintConfig{PackageAverage: 3})
+
+	var packageFindings []ComplexityFinding
+	for _, f := range findings {
+		if f.Kind == "package" {
+			packageFindings = append(packageFindings, f)
+		}
+	}
+	if len(packageFindings) != 1 || packageFindings[0].Package != "p" {
+		t.Fatalf("expected exactly one package finding for p, got %+v", packageFindings)
+	}
+	if packageFindings[0].AverageComplexity != 4 {
+		t.Errorf("average complexity = %v, want 4", packageFindings[0].AverageComplexity)
+	}
+}
+
+// TestRunComplexityLintSkipTestsExcludesTestFiles covers --skip-tests: a
+// _test.go function over threshold must not produce a finding, and must not
+// pull the package average above threshold either.
+func TestRunComplexityLintSkipTestsExcludesTestFiles(t *testing.T) {
+	cfgs := []CFGResult{
+		{FunctionName: "F", Package: "p", File: "p.go", CyclomaticComplexity: 1},
+		{FunctionName: "TestF", Package: "p", File: "p_test.go", CyclomaticComplexity: 9},
+	}
+	findings := RunComplexityLint(cfgs, ComplexityLintConfig{
+		MaxComplexity:  3,
+		PackageAverage: 3,
+		SkipTests:      true,
+	})
+	if len(findings) != 0 {
+		t.Fatalf("expected no findings with SkipTests, got %+v", findings)
+	}
+}
+
+// TestRunComplexityLintDisabledChecksProduceNoFindings covers the
+// MaxComplexity == 0 / PackageAverage == 0 "disabled" defaults.
+func TestRunComplexityLintDisabledChecksProduceNoFindings(t *testing.T) {
+	cfgs := []CFGResult{
+		{FunctionName: "F", Package: "p", File: "p.go", CyclomaticComplexity: 100},
+	}
+	findings := RunComplexityLint(cfgs, ComplexityLintConfig{})
+	if len(findings) != 0 {
+		t.Fatalf("expected no findings when both thresholds are disabled, got %+v", findings)
+	}
+}
+
+// TestWriteComplexityReportWritesJSONAndSARIF covers writeComplexityReport's
+// two output files.
+func TestWriteComplexityReportWritesJSONAndSARIF(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "report")
+	findings := []ComplexityFinding{
+		{Kind: "function", File: "p.go", Line: 10, Function: "F", Complexity: 5, Threshold: 3, Message: "too complex"},
+	}
+
+	if err := writeComplexityReport(basePath, findings); err != nil {
+		t.Fatalf("writeComplexityReport: %v", err)
+	}
+
+	jsonBytes, err := os.ReadFile(basePath + ".json")
+	if err != nil {
+		t.Fatalf("read json report: %v", err)
+	}
+	var roundTripped []ComplexityFinding
+	if err := json.Unmarshal(jsonBytes, &roundTripped); err != nil {
+		t.Fatalf("unmarshal json report: %v", err)
+	}
+	if len(roundTripped) != 1 || roundTripped[0].Function != "F" {
+		t.Fatalf("unexpected JSON report contents: %+v", roundTripped)
+	}
+
+	sarifBytes, err := os.ReadFile(basePath + ".sarif")
+	if err != nil {
+		t.Fatalf("read sarif report: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(sarifBytes, &log); err != nil {
+		t.Fatalf("unmarshal sarif report: %v", err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 1 {
+		t.Fatalf("expected one SARIF run with one result, got %+v", log)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// typeEntityIndex resolves a *types.TypeName's declaration position back to
+// the Entity.ID analyzeTypeDecl assigned it, using the same pkgPath+name+line
+// key entityPositionIndex (callgraph_builder.go) uses to join type-checker
+// objects back to extracted entities - not a file path, since Entity.File is
+// relative to repoPath while fset positions are always absolute, and not the
+// short package name, since two different packages can share one.
+type typeEntityIndex struct {
+	fset  *token.FileSet
+	byKey map[string]string
+}
+
+func newTypeEntityIndex(entities []Entity, fset *token.FileSet) *typeEntityIndex {
+	idx := &typeEntityIndex{fset: fset, byKey: make(map[string]string, len(entities))}
+	for _, e := range entities {
+		switch e.Type {
+		case "struct", "interface", "type":
+			idx.byKey[fmt.Sprintf("%s:%s:%d", e.PkgPath, e.Name, e.StartLine)] = e.ID
+		}
+	}
+	return idx
+}
+
+func (idx *typeEntityIndex) lookup(obj types.Object) (string, bool) {
+	if obj == nil || obj.Pos() == token.NoPos || obj.Pkg() == nil {
+		return "", false
+	}
+	pos := idx.fset.Position(obj.Pos())
+	id, ok := idx.byKey[fmt.Sprintf("%s:%s:%d", obj.Pkg().Path(), obj.Name(), pos.Line)]
+	return id, ok
+}
+
+// namedTypeDecl and ifaceTypeDecl are the two kinds of package-scope type
+// declaration BuildInterfaceGraph's first pass sorts every named type into.
+type namedTypeDecl struct {
+	obj   *types.TypeName
+	named *types.Named
+}
+
+type ifaceTypeDecl struct {
+	obj   *types.TypeName
+	iface *types.Interface
+}
+
+// BuildInterfaceGraph replaces the old single-package extractInterfaceImplementations
+// with a whole-program, two-pass analysis: pass one walks every loaded
+// package's scope and sorts its named types into concrete types and
+// interfaces; pass two checks every (concrete, interface) pair with
+// types.Implements - both by value and via *T, so a pointer-receiver-only
+// implementation is still found - and emits an "implements" edge with both
+// SourceID and TargetID resolved through typeEntityIndex. It also emits
+// "embeds" edges for interface embedding and struct field embedding, which
+// extractInterfaceImplementations never recorded at all.
+func BuildInterfaceGraph(pkgs []*packages.Package, entities []Entity, fset *token.FileSet, includeEmptyInterface bool, counter *int) []Relationship {
+	index := newTypeEntityIndex(entities, fset)
+
+	var concretes []namedTypeDecl
+	var interfaces []ifaceTypeDecl
+	seen := make(map[*types.Named]bool)
+
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			named, ok := obj.Type().(*types.Named)
+			if !ok || seen[named] {
+				continue
+			}
+			seen[named] = true
+
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				interfaces = append(interfaces, ifaceTypeDecl{obj: obj, iface: iface})
+				continue
+			}
+			concretes = append(concretes, namedTypeDecl{obj: obj, named: named})
+		}
+	}
+
+	var relationships []Relationship
+	relationships = append(relationships, implementsEdges(concretes, interfaces, index, includeEmptyInterface, counter)...)
+	relationships = append(relationships, interfaceEmbedEdges(interfaces, index, counter)...)
+	relationships = append(relationships, structEmbedEdges(concretes, index, counter)...)
+	return relationships
+}
+
+// implementsEdges emits one "implements" edge per (concrete type, interface)
+// pair where the concrete type - by value, by pointer, or both - satisfies
+// the interface.
+func implementsEdges(concretes []namedTypeDecl, interfaces []ifaceTypeDecl, index *typeEntityIndex, includeEmptyInterface bool, counter *int) []Relationship {
+	var relationships []Relationship
+	for _, c := range concretes {
+		sourceID, sourceOK := index.lookup(c.obj)
+		if !sourceOK {
+			continue
+		}
+		for _, i := range interfaces {
+			if !includeEmptyInterface && i.iface.NumMethods() == 0 {
+				continue
+			}
+			targetID, targetOK := index.lookup(i.obj)
+			if !targetOK {
+				continue
+			}
+
+			viaValue := types.Implements(c.named, i.iface)
+			viaPointer := types.Implements(types.NewPointer(c.named), i.iface)
+			if !viaValue && !viaPointer {
+				continue
+			}
+
+			*counter++
+			relationships = append(relationships, Relationship{
+				ID:         fmt.Sprintf("rel_%d", *counter),
+				SourceID:   sourceID,
+				SourceName: c.obj.Name(),
+				TargetID:   targetID,
+				TargetName: i.obj.Name(),
+				Type:       "implements",
+				Metadata: map[string]string{
+					"implements_via_pointer": fmt.Sprintf("%t", !viaValue && viaPointer),
+				},
+			})
+		}
+	}
+	return relationships
+}
+
+// interfaceEmbedEdges emits "embeds" edges for every interface embedded
+// directly in another interface's method set.
+func interfaceEmbedEdges(interfaces []ifaceTypeDecl, index *typeEntityIndex, counter *int) []Relationship {
+	var relationships []Relationship
+	for _, i := range interfaces {
+		sourceID, sourceOK := index.lookup(i.obj)
+		if !sourceOK {
+			continue
+		}
+		for j := 0; j < i.iface.NumEmbeddeds(); j++ {
+			embeddedNamed, ok := i.iface.EmbeddedType(j).(*types.Named)
+			if !ok {
+				continue
+			}
+			targetID, targetOK := index.lookup(embeddedNamed.Obj())
+			if !targetOK {
+				continue
+			}
+			*counter++
+			relationships = append(relationships, Relationship{
+				ID:         fmt.Sprintf("rel_%d", *counter),
+				SourceID:   sourceID,
+				SourceName: i.obj.Name(),
+				TargetID:   targetID,
+				TargetName: embeddedNamed.Obj().Name(),
+				Type:       "embeds",
+				Metadata:   map[string]string{"embed_kind": "interface"},
+			})
+		}
+	}
+	return relationships
+}
+
+// structEmbedEdges emits "embeds" edges for every anonymous (embedded)
+// struct field, following through a pointer if the field is embedded by
+// pointer.
+func structEmbedEdges(concretes []namedTypeDecl, index *typeEntityIndex, counter *int) []Relationship {
+	var relationships []Relationship
+	for _, c := range concretes {
+		structType, ok := c.named.Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		sourceID, sourceOK := index.lookup(c.obj)
+		if !sourceOK {
+			continue
+		}
+		for f := 0; f < structType.NumFields(); f++ {
+			field := structType.Field(f)
+			if !field.Embedded() {
+				continue
+			}
+			fieldType := field.Type()
+			if ptr, ok := fieldType.(*types.Pointer); ok {
+				fieldType = ptr.Elem()
+			}
+			embeddedNamed, ok := fieldType.(*types.Named)
+			if !ok {
+				continue
+			}
+			targetID, targetOK := index.lookup(embeddedNamed.Obj())
+			if !targetOK {
+				continue
+			}
+			*counter++
+			relationships = append(relationships, Relationship{
+				ID:         fmt.Sprintf("rel_%d", *counter),
+				SourceID:   sourceID,
+				SourceName: c.obj.Name(),
+				TargetID:   targetID,
+				TargetName: embeddedNamed.Obj().Name(),
+				Type:       "embeds",
+				Metadata:   map[string]string{"embed_kind": "struct_field"},
+			})
+		}
+	}
+	return relationships
+}
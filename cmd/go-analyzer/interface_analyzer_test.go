@@ -0,0 +1,157 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// loadTestPackage writes src as the sole file of a throwaway module under a
+// temp directory and loads it with enough packages.NeedTypes/NeedSyntax to
+// drive BuildInterfaceGraph.
+func loadTestPackage(t *testing.T, src string) ([]*packages.Package, *token.FileSet) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  dir,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			t.Fatalf("package load error: %v", err)
+		}
+	}
+	return pkgs, fset
+}
+
+// entitiesForTypes builds a minimal []Entity covering every top-level type
+// declaration in pkgs, the way extractEntitiesFromFile does, so
+// typeEntityIndex has something to resolve types.TypeName objects against.
+func entitiesForTypes(pkgs []*packages.Package, fset *token.FileSet, kinds map[string]string) []Entity {
+	var entities []Entity
+	n := 0
+	for _, pkg := range pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			kind, ok := kinds[name]
+			if !ok {
+				continue
+			}
+			obj := scope.Lookup(name)
+			pos := fset.Position(obj.Pos())
+			n++
+			entities = append(entities, Entity{
+				ID:        name,
+				Name:      name,
+				Type:      kind,
+				Package:   pkg.Name,
+				PkgPath:   pkg.PkgPath,
+				File:      pos.Filename,
+				StartLine: pos.Line,
+			})
+		}
+	}
+	return entities
+}
+
+// TestBuildInterfaceGraphFindsValueAndPointerImplementations covers the core
+// types.Implements check: a value-receiver type implementing an interface,
+// and a pointer-receiver-only type that only satisfies it via *T.
+func TestBuildInterfaceGraphFindsValueAndPointerImplementations(t *testing.T) {
+	src := `package p
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct{ R float64 }
+func (c Circle) Area() float64 { return c.R }
+
+type Square struct{ S float64 }
+func (s *Square) Area() float64 { return s.S }
+`
+	pkgs, fset := loadTestPackage(t, src)
+	entities := entitiesForTypes(pkgs, fset, map[string]string{
+		"Shape": "interface", "Circle": "struct", "Square": "struct",
+	})
+
+	counter := 0
+	rels := BuildInterfaceGraph(pkgs, entities, fset, false, &counter)
+
+	byPair := make(map[[2]string]Relationship)
+	for _, r := range rels {
+		if r.Type == "implements" {
+			byPair[[2]string{r.SourceName, r.TargetName}] = r
+		}
+	}
+
+	circle, ok := byPair[[2]string{"Circle", "Shape"}]
+	if !ok {
+		t.Fatal("expected an implements edge from Circle to Shape (value receiver)")
+	}
+	if circle.Metadata["implements_via_pointer"] != "false" {
+		t.Errorf("Circle implements Shape by value, want implements_via_pointer=false, got %q", circle.Metadata["implements_via_pointer"])
+	}
+
+	square, ok := byPair[[2]string{"Square", "Shape"}]
+	if !ok {
+		t.Fatal("expected an implements edge from Square to Shape (pointer receiver)")
+	}
+	if square.Metadata["implements_via_pointer"] != "true" {
+		t.Errorf("Square only implements Shape via *Square, want implements_via_pointer=true, got %q", square.Metadata["implements_via_pointer"])
+	}
+}
+
+// TestBuildInterfaceGraphEmptyInterfaceFlag covers includeEmptyInterface:
+// every concrete type trivially satisfies interface{}, so it must be
+// excluded unless the flag asks for it.
+func TestBuildInterfaceGraphEmptyInterfaceFlag(t *testing.T) {
+	src := `package p
+
+type Anything interface{}
+
+type Box struct{}
+`
+	pkgs, fset := loadTestPackage(t, src)
+	entities := entitiesForTypes(pkgs, fset, map[string]string{
+		"Anything": "interface", "Box": "struct",
+	})
+
+	counter := 0
+	withoutEmpty := BuildInterfaceGraph(pkgs, entities, fset, false, &counter)
+	for _, r := range withoutEmpty {
+		if r.Type == "implements" {
+			t.Errorf("expected no implements edges to the empty interface by default, got %+v", r)
+		}
+	}
+
+	counter = 0
+	withEmpty := BuildInterfaceGraph(pkgs, entities, fset, true, &counter)
+	found := false
+	for _, r := range withEmpty {
+		if r.Type == "implements" && r.SourceName == "Box" && r.TargetName == "Anything" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected an implements edge to the empty interface when includeEmptyInterface is true")
+	}
+}
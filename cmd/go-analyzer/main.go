@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -21,6 +20,7 @@ type Entity struct {
 	Name         string            `json:"name"`
 	Type         string            `json:"type"`
 	Package      string            `json:"package"`
+	PkgPath      string            `json:"pkg_path,omitempty"`
 	File         string            `json:"file"`
 	StartLine    int               `json:"start_line"`
 	EndLine      int               `json:"end_line"`
@@ -59,12 +59,16 @@ type CFGNode struct {
 
 // CFGResult represents the control flow graph for a function
 type CFGResult struct {
-	FunctionName        string    `json:"function_name"`
-	FunctionID          string    `json:"function_id"`
-	Nodes               []CFGNode `json:"nodes"`
-	EdgeCount           int       `json:"edge_count"`
-	CyclomaticComplexity int      `json:"cyclomatic_complexity"`
-	UnreachableBlocks   []int     `json:"unreachable_blocks"`
+	FunctionName        string     `json:"function_name"`
+	FunctionID          string     `json:"function_id"`
+	Package             string     `json:"package,omitempty"`
+	File                string     `json:"file,omitempty"`
+	Nodes               []CFGNode  `json:"nodes"`
+	EdgeCount           int        `json:"edge_count"`
+	CyclomaticComplexity int       `json:"cyclomatic_complexity"`
+	UnreachableBlocks   []int      `json:"unreachable_blocks"`
+	SSA                 *SSAResult `json:"ssa,omitempty"`
+	Findings            []CFGFinding `json:"findings,omitempty"`
 }
 
 // ControlFlowAnalysis contains all CFG results
@@ -80,7 +84,16 @@ type ControlFlowAnalysis struct {
 
 // DeepAnalysisFlags controls which deep analysis features to enable
 type DeepAnalysisFlags struct {
-	EnableCFG bool
+	EnableCFG    bool
+	EnableSSA    bool
+	SSASanity    bool
+	EnableChecks bool
+
+	// Complexity lint mode (see complexity_linter.go). MaxComplexity/
+	// PackageAverage of 0 disable the respective check.
+	MaxComplexity  int
+	PackageAverage float64
+	SkipTests      bool
 }
 
 // DeepAnalysis contains advanced static analysis results
@@ -97,6 +110,9 @@ type AnalysisResult struct {
 	Relationships []Relationship `json:"relationships"`
 	DeepAnalysis  *DeepAnalysis  `json:"deep_analysis,omitempty"`
 	Stats         AnalysisStats  `json:"stats"`
+	ComplexityFindings []ComplexityFinding `json:"complexity_findings,omitempty"`
+	Diagnostics        []Diagnostic        `json:"diagnostics,omitempty"`
+	Unused             []string            `json:"unused,omitempty"`
 }
 
 // AnalysisStats contains analysis statistics
@@ -117,7 +133,26 @@ func main() {
 		verbose          = flag.Bool("verbose", false, "Enable verbose logging")
 		pattern          = flag.String("pattern", "./...", "Go package pattern to analyze")
 		enableCFG        = flag.Bool("enable-cfg", false, "Enable Control Flow Graph analysis")
+		enableSSA        = flag.Bool("enable-ssa", false, "Enable pruned SSA construction on top of the CFG")
+		ssaSanity        = flag.Bool("ssa-sanity", false, "Cross-check the Lengauer-Tarjan dominator tree against a naive iterative computation")
 		enableDeepAnalysis = flag.Bool("enable-deep-analysis", false, "Enable all deep analysis features")
+		maxComplexity    = flag.Int("max-complexity", 0, "Report functions whose cyclomatic complexity exceeds this value (0 disables the check)")
+		packageAverage   = flag.Float64("package-average", 0, "Report packages whose mean function complexity exceeds this value (0 disables the check)")
+		skipTests        = flag.Bool("skip-tests", false, "Exclude _test.go files from the complexity lint")
+		complexityReport = flag.String("complexity-report", "", "Write complexity lint findings to <path>.json and <path>.sarif")
+		enableChecks     = flag.Bool("enable-checks", false, "Enable CFG-driven static checks (unreachable code, missing returns, constant conditions, unchecked errors)")
+		failOn           = flag.String("fail-on", "", "Exit with a nonzero status if any CFG finding at or above this severity is found: warning|error")
+		callgraphAlgo    = flag.String("callgraph", "", "Build a precise SSA-based call graph using the given algorithm: cha|rta|vta|static (disabled by default)")
+		analyzers        = flag.String("analyzers", "", "Comma-separated go/analysis analyzers to run (e.g. nilness,printf,shadow)")
+		cacheDir         = flag.String("cache-dir", DefaultCacheDir(), "Directory for the content-addressed per-package analysis cache")
+		noCache          = flag.Bool("no-cache", false, "Disable the per-package analysis cache")
+		cacheMaxBytes    = flag.Int64("cache-max-bytes", 512*1024*1024, "Garbage-collect the analysis cache down to this size after each run (0 disables GC)")
+		includeEmptyInterface = flag.Bool("include-empty-interface", false, "Also emit \"implements\" edges for the empty interface (interface{}/any), which every type trivially satisfies")
+		outputFormat     = flag.String("output-format", "json", "Output format: json|dot|graphml|cytoscape")
+		focus            = flag.String("focus", "", "Restrict output to the induced subgraph within -depth hops of entities whose name matches this glob")
+		depth            = flag.Int("depth", 2, "Number of relationship hops to include around -focus matches")
+		detectUnused     = flag.Bool("detect-unused", false, "Mark-and-sweep every entity from main/init/exported-identifier/reflection roots and annotate anything unreached as unused")
+		unusedGraph      = flag.String("unused-graph", "", "Dump the -detect-unused reachability graph as Graphviz DOT to this path")
 	)
 	flag.Parse()
 
@@ -129,11 +164,43 @@ func main() {
 	}
 
 	// Set deep analysis flags
+	lintRequested := *maxComplexity > 0 || *packageAverage > 0 || *complexityReport != ""
 	deepFlags := DeepAnalysisFlags{
-		EnableCFG: *enableCFG || *enableDeepAnalysis,
+		EnableCFG:      *enableCFG || *enableDeepAnalysis || lintRequested || *enableChecks,
+		EnableSSA:      *enableSSA || *enableDeepAnalysis,
+		SSASanity:      *ssaSanity,
+		EnableChecks:   *enableChecks || *enableDeepAnalysis,
+		MaxComplexity:  *maxComplexity,
+		PackageAverage: *packageAverage,
+		SkipTests:      *skipTests,
 	}
 	
-	result := analyzeGoRepository(*repoPath, *pattern, *includeCode, *verbose, deepFlags)
+	cacheFlags := PackageCacheFlags{
+		Dir:      *cacheDir,
+		Disabled: *noCache,
+		MaxBytes: *cacheMaxBytes,
+	}
+
+	unusedFlags := UnusedFlags{
+		Enabled:   *detectUnused,
+		GraphFile: *unusedGraph,
+	}
+
+	result := analyzeGoRepository(*repoPath, *pattern, *includeCode, *verbose, deepFlags, *callgraphAlgo, *analyzers, cacheFlags, *includeEmptyInterface, unusedFlags)
+
+	if *complexityReport != "" {
+		if err := writeComplexityReport(*complexityReport, result.ComplexityFindings); err != nil {
+			log.Fatalf("Failed to write complexity report: %v", err)
+		}
+	}
+
+	if *focus != "" {
+		var err error
+		result, err = FocusSubgraph(result, *focus, *depth)
+		if err != nil {
+			log.Fatalf("Failed to apply -focus: %v", err)
+		}
+	}
 
 	var output *os.File
 	var err error
@@ -147,14 +214,32 @@ func main() {
 		output = os.Stdout
 	}
 
-	encoder := json.NewEncoder(output)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(result); err != nil {
-		log.Fatalf("Failed to encode JSON: %v", err)
+	if err := Render(output, result, *outputFormat); err != nil {
+		log.Fatalf("Failed to render output as %s: %v", *outputFormat, err)
 	}
+
+	if *failOn != "" && hasFindingAtOrAbove(result, *failOn) {
+		os.Exit(1)
+	}
+}
+
+// hasFindingAtOrAbove reports whether any CFG finding meets or exceeds the
+// requested severity ("warning" or "error"; "error" is more severe).
+func hasFindingAtOrAbove(result AnalysisResult, minSeverity string) bool {
+	if result.DeepAnalysis == nil || result.DeepAnalysis.ControlFlow == nil {
+		return false
+	}
+	for _, fn := range result.DeepAnalysis.ControlFlow.Functions {
+		for _, finding := range fn.Findings {
+			if minSeverity == "warning" || finding.Severity == "error" {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, deepFlags DeepAnalysisFlags) AnalysisResult {
+func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, deepFlags DeepAnalysisFlags, callgraphAlgo string, analyzerNames string, cacheFlags PackageCacheFlags, includeEmptyInterface bool, unusedFlags UnusedFlags) AnalysisResult {
 	if verbose {
 		log.Printf("Analyzing Go repository at: %s with pattern: %s", repoPath, pattern)
 	}
@@ -171,8 +256,8 @@ func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, de
 	// Configure package loading
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles |
-			packages.NeedImports | packages.NeedTypes |
-			packages.NeedTypesInfo | packages.NeedSyntax,
+			packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedTypesInfo | packages.NeedTypesSizes | packages.NeedSyntax,
 		Dir:        ".",
 		BuildFlags: []string{"-tags=ignore_build_constraints"},
 		Env:        append(os.Environ(), "GO111MODULE=off"), // For single files
@@ -208,21 +293,64 @@ func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, de
 	relationshipCounter := 0
 	var fset *token.FileSet
 
+	// Set up the content-addressed per-package cache. A package's key folds
+	// in its own file contents and the already-computed keys of its direct
+	// imports (computePackageKeys walks the import graph in postorder), so
+	// any change to a package or a transitive dependency invalidates it and
+	// everything downstream of it without re-hashing the whole tree.
+	//
+	// This only skips re-extracting entities/relationships (and, when
+	// enabled, CFG/diagnostics) for an unchanged package - the packages.Load
+	// call above still parses and type-checks every package on every run,
+	// since that happens before any cache key can be computed. It cuts the
+	// AST-walk cost, not the parse/typecheck cost.
+	var pkgCache *PackageCache
+	var pkgKeys map[string]string
+	if !cacheFlags.Disabled {
+		pkgCache = NewPackageCache(cacheFlags.Dir, cacheFlags.MaxBytes)
+		flagsSig := cacheFlagsSignature(includeCode, deepFlags, callgraphAlgo, analyzerNames)
+		if keys, err := computePackageKeys(pkgCache, pkgs, flagsSig); err != nil {
+			if verbose {
+				log.Printf("Disabling analysis cache for this run: %v", err)
+			}
+			pkgCache = nil
+		} else {
+			pkgKeys = keys
+		}
+	}
+
 	// Analyze each package
 	for _, pkg := range pkgs {
 		if len(pkg.Errors) > 0 {
 			continue // Skip packages with errors
 		}
 
+		fset = pkg.Fset
+
+		key := pkgKeys[pkg.PkgPath]
+		if pkgCache != nil {
+			if entry, hit := pkgCache.Load(key); hit {
+				if verbose {
+					log.Printf("Cache hit for package %s", pkg.Name)
+				}
+				entities = append(entities, entry.Entities...)
+				relationships = append(relationships, entry.Relationships...)
+				continue
+			}
+		}
+
 		if verbose {
 			log.Printf("Analyzing package: %s (%d files)", pkg.Name, len(pkg.Syntax))
 		}
 
+		var pkgEntities []Entity
+		var pkgRelationships []Relationship
+
 		// Analyze each file in the package
 		for _, file := range pkg.Syntax {
 			fset = pkg.Fset
 			filename := fset.Position(file.Pos()).Filename
-			
+
 			// Make filename relative to repo root
 			if relPath, err := filepath.Rel(repoPath, filename); err == nil {
 				filename = relPath
@@ -231,14 +359,76 @@ func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, de
 			// Extract entities from this file
 			fileEntities, fileRelationships := extractEntitiesFromFile(
 				file, pkg, fset, filename, includeCode, &entityCounter, &relationshipCounter)
-			
-			entities = append(entities, fileEntities...)
-			relationships = append(relationships, fileRelationships...)
+
+			pkgEntities = append(pkgEntities, fileEntities...)
+			pkgRelationships = append(pkgRelationships, fileRelationships...)
+		}
+
+		entities = append(entities, pkgEntities...)
+		relationships = append(relationships, pkgRelationships...)
+
+		if pkgCache != nil {
+			if err := pkgCache.Store(key, &PackageCacheEntry{Entities: pkgEntities, Relationships: pkgRelationships}); err != nil && verbose {
+				log.Printf("Failed to cache package %s: %v", pkg.Name, err)
+			}
+		}
+	}
+
+	// Cross-package interface implementation and embedding edges (always on
+	// - BuildInterfaceGraph is the load-bearing replacement for the old,
+	// single-package extractInterfaceImplementations, not an opt-in extra).
+	relationships = append(relationships, BuildInterfaceGraph(pkgs, entities, fset, includeEmptyInterface, &relationshipCounter)...)
+
+	// -detect-unused's reachability sweep walks "calls" relationships looking
+	// for a resolved TargetID; the legacy AST-based call extraction never
+	// sets one, so without a real call graph every privately-called function
+	// looks unreachable and gets falsely flagged as dead code. Force the SSA
+	// call graph to build (defaulting to CHA, the cheapest whole-program
+	// algorithm) when detect-unused is on, even if -callgraph was omitted.
+	effectiveCallgraphAlgo := callgraphAlgo
+	if effectiveCallgraphAlgo == "" && unusedFlags.Enabled {
+		effectiveCallgraphAlgo = CallGraphCHA
+	}
+	if effectiveCallgraphAlgo != "" {
+		if verbose {
+			log.Printf("Building SSA call graph using %s", effectiveCallgraphAlgo)
+		}
+		callRels, err := BuildSSACallGraph(pkgs, fset, entities, effectiveCallgraphAlgo, &relationshipCounter)
+		if err != nil {
+			log.Printf("SSA call graph construction failed: %v", err)
+			if unusedFlags.Enabled {
+				log.Printf("-detect-unused results will be unreliable: no call graph to resolve \"calls\" edges against")
+			}
+		} else {
+			relationships = append(relationships, callRels...)
 		}
+	}
 
-		// Extract interface implementations
-		interfaceRels := extractInterfaceImplementations(pkg, &relationshipCounter)
-		relationships = append(relationships, interfaceRels...)
+	var diagnostics []Diagnostic
+	if analyzerNames != "" {
+		names := strings.Split(analyzerNames, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		selected, err := ResolveAnalyzers(names)
+		if err != nil {
+			log.Printf("Skipping analysis driver: %v", err)
+		} else {
+			if verbose {
+				log.Printf("Running go/analysis driver with: %s", analyzerNames)
+			}
+			diagnostics = NewAnalysisDriver(fset, selected).Run(pkgs)
+			relationships = append(relationships, diagnosticRelationships(entities, diagnostics, &relationshipCounter)...)
+
+			// Cache each package's diagnostics under its own slot. Facts still
+			// have to be recomputed on every run for correct cross-package
+			// propagation (a cache hit here only warms external readers of
+			// the cache, e.g. a future "show cached diagnostics" mode; it
+			// does not yet let runPackage skip re-analysis).
+			if pkgCache != nil {
+				storeDiagnosticsByPackage(pkgCache, pkgs, pkgKeys, diagnostics)
+			}
+		}
 	}
 
 	// Perform deep analysis if enabled
@@ -249,28 +439,83 @@ func analyzeGoRepository(repoPath, pattern string, includeCode, verbose bool, de
 		}
 		
 		deepAnalysis = &DeepAnalysis{}
-		
-		// Control Flow Graph analysis (using simple implementation to avoid crashes)
-		cfgAnalyzer := NewSimpleCFGAnalyzer(fset, verbose)
+
+		// Control Flow Graph analysis
+		cfgAnalyzer := NewBasicBlockCFGAnalyzer(fset, verbose)
+		cfgAnalyzer.enableSSA = deepFlags.EnableSSA
+		cfgAnalyzer.ssaSanity = deepFlags.SSASanity
+		cfgAnalyzer.enableChecks = deepFlags.EnableChecks
+		cfgAnalyzer.cache = pkgCache
+		cfgAnalyzer.pkgKeys = pkgKeys
 		cfgResults := cfgAnalyzer.AnalyzeControlFlow(pkgs)
 		deepAnalysis.ControlFlow = &cfgResults
 		
 		if verbose {
-			log.Printf("CFG analysis completed: %d functions analyzed, average complexity %.2f", 
+			log.Printf("CFG analysis completed: %d functions analyzed, average complexity %.2f",
 				cfgResults.Summary.TotalFunctions, cfgResults.Summary.AverageComplexity)
 		}
 	}
 
+	var complexityFindings []ComplexityFinding
+	if deepAnalysis != nil && deepAnalysis.ControlFlow != nil {
+		annotateComplexity(entities, deepAnalysis.ControlFlow.Functions)
+		if deepFlags.MaxComplexity > 0 || deepFlags.PackageAverage > 0 {
+			complexityFindings = RunComplexityLint(deepAnalysis.ControlFlow.Functions, ComplexityLintConfig{
+				MaxComplexity:  deepFlags.MaxComplexity,
+				PackageAverage: deepFlags.PackageAverage,
+				SkipTests:      deepFlags.SkipTests,
+			})
+		}
+	}
+
+	if pkgCache != nil {
+		if err := pkgCache.GC(); err != nil && verbose {
+			log.Printf("Analysis cache GC failed: %v", err)
+		}
+	}
+
+	var unused []string
+	if unusedFlags.Enabled {
+		unusedAnalysis := DetectUnused(pkgs, fset, entities, relationships)
+		unused = unusedAnalysis.Unused
+
+		unusedSet := make(map[string]bool, len(unused))
+		for _, id := range unused {
+			unusedSet[id] = true
+		}
+		for i := range entities {
+			if unusedSet[entities[i].ID] {
+				if entities[i].Metadata == nil {
+					entities[i].Metadata = make(map[string]string)
+				}
+				entities[i].Metadata["unused"] = "true"
+			}
+		}
+
+		if unusedFlags.GraphFile != "" {
+			if err := writeUnusedGraph(unusedFlags.GraphFile, unusedAnalysis, entities); err != nil {
+				log.Printf("Failed to write unused reachability graph: %v", err)
+			}
+		}
+
+		if verbose {
+			log.Printf("Unused-symbol sweep: %d roots, %d of %d entities unreached", len(unusedAnalysis.Roots), len(unused), len(entities))
+		}
+	}
+
 	// Calculate statistics
 	stats := calculateStats(entities, relationships, pkgs)
 
 	result := AnalysisResult{
-		Success:       true,
-		Language:      "go",
-		Entities:      entities,
-		Relationships: relationships,
-		DeepAnalysis:  deepAnalysis,
-		Stats:         stats,
+		Success:            true,
+		Language:           "go",
+		Entities:           entities,
+		Relationships:      relationships,
+		DeepAnalysis:       deepAnalysis,
+		Stats:              stats,
+		ComplexityFindings: complexityFindings,
+		Diagnostics:        diagnostics,
+		Unused:             unused,
 	}
 
 	// Add package errors as metadata if any
@@ -339,6 +584,7 @@ func analyzeFuncDecl(fn *ast.FuncDecl, pkg *packages.Package, fset *token.FileSe
 		Name:     fn.Name.Name,
 		Type:     "function",
 		Package:  pkg.Name,
+		PkgPath:  pkg.PkgPath,
 		File:     filename,
 		StartLine: startPos.Line,
 		EndLine:   endPos.Line,
@@ -414,6 +660,7 @@ func analyzeTypeDecl(typeSpec *ast.TypeSpec, pkg *packages.Package, fset *token.
 		Name:     typeSpec.Name.Name,
 		Type:     "type",
 		Package:  pkg.Name,
+		PkgPath:  pkg.PkgPath,
 		File:     filename,
 		StartLine: startPos.Line,
 		EndLine:   endPos.Line,
@@ -505,6 +752,7 @@ func analyzeValueDecl(name *ast.Ident, spec *ast.ValueSpec, tok token.Token, pkg
 		Name:     name.Name,
 		Type:     entityType,
 		Package:  pkg.Name,
+		PkgPath:  pkg.PkgPath,
 		File:     filename,
 		StartLine: startPos.Line,
 		EndLine:   startPos.Line,
@@ -578,40 +826,6 @@ func extractFunctionCalls(fn *ast.FuncDecl, sourceID string, pkg *packages.Packa
 	return relationships
 }
 
-func extractInterfaceImplementations(pkg *packages.Package, counter *int) []Relationship {
-	var relationships []Relationship
-	
-	// This is a simplified implementation - in practice, you'd do more comprehensive analysis
-	for _, obj := range pkg.TypesInfo.Defs {
-		if obj != nil && obj.Type() != nil {
-			// Check if this type implements any interfaces
-			// This would require more sophisticated analysis to be complete
-			if named, ok := obj.Type().(*types.Named); ok {
-				for i := 0; i < named.NumMethods(); i++ {
-					method := named.Method(i)
-					// Create method relationship
-					*counter++
-					relationship := Relationship{
-						ID:         fmt.Sprintf("rel_%d", *counter),
-						SourceID:   "", // Would need to resolve
-						SourceName: obj.Name(),
-						TargetID:   "",
-						TargetName: method.Name(),
-						Type:       "defines_method",
-						Metadata:   map[string]string{
-							"analysis_type": "method_definition",
-							"method_signature": method.Type().String(),
-						},
-					}
-					relationships = append(relationships, relationship)
-				}
-			}
-		}
-	}
-	
-	return relationships
-}
-
 func calculateStats(entities []Entity, relationships []Relationship, pkgs []*packages.Package) AnalysisStats {
 	stats := AnalysisStats{
 		TotalEntities:      len(entities),
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// PackageCacheEntry is everything a cached package carries: the entities
+// and relationships extracted from it, its per-function CFG results, and
+// any analyzer diagnostics computed for it.
+type PackageCacheEntry struct {
+	Entities      []Entity       `json:"entities"`
+	Relationships []Relationship `json:"relationships"`
+	CFG           []CFGResult    `json:"cfg,omitempty"`
+	Diagnostics   []Diagnostic   `json:"diagnostics,omitempty"`
+}
+
+// PackageCacheFlags mirrors the -cache-dir/-no-cache/-cache-max-bytes flags.
+type PackageCacheFlags struct {
+	Dir      string
+	Disabled bool
+	MaxBytes int64
+}
+
+// PackageCache is a file-backed, content-addressed cache of per-package
+// analysis results, keyed by a Merkle-style hash of the package's own file
+// contents plus the (already-computed) cache keys of its direct imports -
+// the same style of key gopls's package cache uses, so a change anywhere in
+// a dependency chain invalidates every package downstream of it without
+// needing to hash the whole transitive source tree on every run.
+type PackageCache struct {
+	dir      string
+	maxBytes int64
+}
+
+// NewPackageCache creates a cache rooted at dir (created lazily on first
+// Store). maxBytes <= 0 disables GC.
+func NewPackageCache(dir string, maxBytes int64) *PackageCache {
+	return &PackageCache{dir: dir, maxBytes: maxBytes}
+}
+
+// DefaultCacheDir returns $XDG_CACHE_HOME/code-to-graph (or the OS
+// equivalent via os.UserCacheDir).
+func DefaultCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "code-to-graph")
+	}
+	return filepath.Join(base, "code-to-graph")
+}
+
+// Key computes the content-addressed cache key for pkg: a hash of the
+// analyzer/CFG flag signature, the compiler version, the sorted contents of
+// every file in the package, and the already-computed cache keys of its
+// direct imports (importKeys). Callers must process packages in import
+// postorder so importKeys is fully populated by the time a package's key is
+// computed.
+func (c *PackageCache) Key(pkg *packages.Package, flagsSignature string, importKeys map[string]string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "code-to-graph-cache-v1\n%s\n%s\n", runtime.Version(), flagsSignature)
+
+	files := append([]string(nil), pkg.GoFiles...)
+	sort.Strings(files)
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s for cache key: %w", f, err)
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(h, "file:%s:%x\n", f, sum)
+	}
+
+	var imports []string
+	for path := range pkg.Imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(h, "import:%s:%s\n", path, importKeys[path])
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *PackageCache) path(key string) string {
+	if len(key) < 2 {
+		return filepath.Join(c.dir, key+".json")
+	}
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Load reads a cached entry for key, if present.
+func (c *PackageCache) Load(key string) (*PackageCacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry PackageCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	// Touch the file so GC's LRU sweep sees it as recently used.
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+	return &entry, true
+}
+
+// Store writes entry under key.
+func (c *PackageCache) Store(key string, entry *PackageCacheEntry) error {
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// GC deletes least-recently-used cache entries until the cache is at or
+// under maxBytes. It is a no-op when maxBytes <= 0.
+func (c *PackageCache) GC() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime int64
+	}
+	var entries []entry
+	var total int64
+
+	err := filepath.Walk(c.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		entries = append(entries, entry{path: p, size: info.Size(), modTime: info.ModTime().UnixNano()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil // nothing cached yet
+	}
+
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime < entries[j].modTime })
+
+	for _, e := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil {
+			continue
+		}
+		total -= e.size
+	}
+	return nil
+}
+
+// computePackageKeys computes a Merkle-style cache key for every package
+// reachable from pkgs (including transitive imports), visiting the import
+// graph in postorder so each package's key already has its dependencies'
+// keys available.
+func computePackageKeys(c *PackageCache, pkgs []*packages.Package, flagsSignature string) (map[string]string, error) {
+	keys := make(map[string]string)
+	var visitErr error
+
+	var visit func(pkg *packages.Package)
+	visit = func(pkg *packages.Package) {
+		if pkg == nil || visitErr != nil {
+			return
+		}
+		if _, done := keys[pkg.PkgPath]; done {
+			return
+		}
+		for _, imp := range pkg.Imports {
+			visit(imp)
+		}
+		key, err := c.Key(pkg, flagsSignature, keys)
+		if err != nil {
+			visitErr = err
+			return
+		}
+		keys[pkg.PkgPath] = key
+	}
+
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+	return keys, visitErr
+}
+
+// cfgCacheKey and diagCacheKey derive separate cache slots from a package's
+// base key, so the CFG pass and the analysis driver can cache their own
+// results independently of the entity/relationship extraction pass (which
+// runs first and populates the base key).
+func cfgCacheKey(baseKey string) string  { return baseKey + "-cfg" }
+func diagCacheKey(baseKey string) string { return baseKey + "-diag" }
+
+// storeDiagnosticsByPackage buckets a flat diagnostics slice by the package
+// whose GoFiles contains each diagnostic's file, and stores each bucket
+// under that package's diagCacheKey slot.
+func storeDiagnosticsByPackage(c *PackageCache, pkgs []*packages.Package, keys map[string]string, diagnostics []Diagnostic) {
+	byFile := make(map[string]*packages.Package)
+	for _, pkg := range pkgs {
+		for _, f := range pkg.GoFiles {
+			byFile[f] = pkg
+		}
+	}
+
+	byPkg := make(map[*packages.Package][]Diagnostic)
+	for _, d := range diagnostics {
+		if pkg, ok := byFile[d.File]; ok {
+			byPkg[pkg] = append(byPkg[pkg], d)
+		}
+	}
+
+	for pkg, diags := range byPkg {
+		key, ok := keys[pkg.PkgPath]
+		if !ok {
+			continue
+		}
+		_ = c.Store(diagCacheKey(key), &PackageCacheEntry{Diagnostics: diags})
+	}
+}
+
+// cacheFlagsSignature folds every flag that changes what gets extracted or
+// computed for a package into one string, so the cache key changes whenever
+// an invocation's flags would have produced different output.
+func cacheFlagsSignature(includeCode bool, deepFlags DeepAnalysisFlags, callgraphAlgo, analyzerNames string) string {
+	return fmt.Sprintf("include_code=%t;cfg=%t;ssa=%t;ssa_sanity=%t;checks=%t;max_complexity=%d;package_average=%g;skip_tests=%t;callgraph=%s;analyzers=%s",
+		includeCode, deepFlags.EnableCFG, deepFlags.EnableSSA, deepFlags.SSASanity, deepFlags.EnableChecks,
+		deepFlags.MaxComplexity, deepFlags.PackageAverage, deepFlags.SkipTests, callgraphAlgo, analyzerNames)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// writeTempGoFile writes content to a new file under dir and returns its path.
+func writeTempGoFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+// TestPackageCacheKeyDeterministicAndContentSensitive covers the
+// content-addressed cache key: the same package contents and flag signature
+// must hash to the same key, but changing a source file's contents (or an
+// import's already-computed key) must change it.
+func TestPackageCacheKeyDeterministicAndContentSensitive(t *testing.T) {
+	dir := t.TempDir()
+	f := writeTempGoFile(t, dir, "p.go", "package p\n\nfunc F() {}\n")
+
+	c := NewPackageCache(t.TempDir(), 0)
+	pkg := &packages.Package{PkgPath: "p", GoFiles: []string{f}}
+
+	key1, err := c.Key(pkg, "sig", nil)
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	key2, err := c.Key(pkg, "sig", nil)
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if key1 != key2 {
+		t.Errorf("Key is not deterministic: %s != %s", key1, key2)
+	}
+
+	if err := os.WriteFile(f, []byte("package p\n\nfunc F() { _ = 1 }\n"), 0644); err != nil {
+		t.Fatalf("rewrite %s: %v", f, err)
+	}
+	key3, err := c.Key(pkg, "sig", nil)
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if key3 == key1 {
+		t.Error("Key did not change after editing the package's source file")
+	}
+
+	key4, err := c.Key(pkg, "other-sig", nil)
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if key4 == key3 {
+		t.Error("Key did not change after the flag signature changed")
+	}
+
+	withImport, err := c.Key(pkg, "sig", map[string]string{"other": "dep-key-a"})
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	pkg.Imports = map[string]*packages.Package{"other": {PkgPath: "other"}}
+	withImportA, err := c.Key(pkg, "sig", map[string]string{"other": "dep-key-a"})
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	withImportB, err := c.Key(pkg, "sig", map[string]string{"other": "dep-key-b"})
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if withImport == withImportA {
+		t.Error("Key should change once the package has an import to fold in")
+	}
+	if withImportA == withImportB {
+		t.Error("Key did not change when a direct import's own cache key changed")
+	}
+}
+
+// TestPackageCacheStoreLoadRoundTrip covers the Store/Load round trip that
+// computePackageKeys' postorder traversal and the main analysis loop rely on.
+func TestPackageCacheStoreLoadRoundTrip(t *testing.T) {
+	c := NewPackageCache(t.TempDir(), 0)
+	entry := &PackageCacheEntry{Entities: []Entity{{ID: "e1", Name: "F"}}}
+
+	if err := c.Store("deadbeef", entry); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	got, ok := c.Load("deadbeef")
+	if !ok {
+		t.Fatal("Load returned !ok for a key that was just Stored")
+	}
+	if len(got.Entities) != 1 || got.Entities[0].ID != "e1" {
+		t.Errorf("Load returned %+v, want the stored entry", got)
+	}
+
+	if _, ok := c.Load("never-stored"); ok {
+		t.Error("Load returned ok for a key that was never Stored")
+	}
+}
+
+// TestComputePackageKeysVisitsImportsFirst covers the postorder requirement
+// computePackageKeys documents: a package's key must be computed only after
+// every direct import's key is already available, so that changing a
+// dependency's source changes every package downstream of it.
+func TestComputePackageKeysVisitsImportsFirst(t *testing.T) {
+	dir := t.TempDir()
+	depFile := writeTempGoFile(t, dir, "dep.go", "package dep\n\nfunc D() {}\n")
+	mainFile := writeTempGoFile(t, dir, "main.go", "package main\n\nfunc M() {}\n")
+
+	dep := &packages.Package{PkgPath: "dep", GoFiles: []string{depFile}}
+	root := &packages.Package{
+		PkgPath: "main",
+		GoFiles: []string{mainFile},
+		Imports: map[string]*packages.Package{"dep": dep},
+	}
+
+	c := NewPackageCache(t.TempDir(), 0)
+	keys, err := computePackageKeys(c, []*packages.Package{root}, "sig")
+	if err != nil {
+		t.Fatalf("computePackageKeys: %v", err)
+	}
+	if _, ok := keys["dep"]; !ok {
+		t.Fatal("computePackageKeys did not compute a key for the transitively-visited import \"dep\"")
+	}
+
+	if err := os.WriteFile(depFile, []byte("package dep\n\nfunc D() { _ = 1 }\n"), 0644); err != nil {
+		t.Fatalf("rewrite %s: %v", depFile, err)
+	}
+	keys2, err := computePackageKeys(c, []*packages.Package{root}, "sig")
+	if err != nil {
+		t.Fatalf("computePackageKeys: %v", err)
+	}
+	if keys2["main"] == keys["main"] {
+		t.Error("root package's key did not change after its import's source changed")
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Render writes result to w in the requested format: json (the default,
+// unchanged wire format), dot (Graphviz), graphml (yEd/Gephi) or cytoscape
+// (Cytoscape.js JSON).
+func Render(w io.Writer, result AnalysisResult, format string) error {
+	switch format {
+	case "", "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	case "dot":
+		_, err := io.WriteString(w, RenderDOT(result))
+		return err
+	case "graphml":
+		return RenderGraphML(w, result)
+	case "cytoscape":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(toCytoscape(result))
+	default:
+		return fmt.Errorf("unknown output format %q (want one of json, dot, graphml, cytoscape)", format)
+	}
+}
+
+// dotShapeByKind and dotStyleByKind give the renders a consistent, readable
+// look: one shape per entity kind, one edge style per relationship kind.
+// Kinds not listed fall back to a sensible default.
+var dotShapeByKind = map[string]string{
+	"function":  "ellipse",
+	"method":    "ellipse",
+	"struct":    "box",
+	"interface": "hexagon",
+	"type":      "box",
+	"var":       "note",
+	"const":     "note",
+}
+
+var dotStyleByKind = map[string]string{
+	"calls":           "solid",
+	"implements":      "dashed",
+	"embeds":          "dotted",
+	"has_diagnostic":  "bold",
+}
+
+func dotShape(kind string) string {
+	if shape, ok := dotShapeByKind[kind]; ok {
+		return shape
+	}
+	return "plaintext"
+}
+
+func dotStyle(kind string) string {
+	if style, ok := dotStyleByKind[kind]; ok {
+		return style
+	}
+	return "solid"
+}
+
+// RenderDOT renders result as a Graphviz DOT digraph, with entities grouped
+// into one cluster subgraph per package.
+func RenderDOT(result AnalysisResult) string {
+	var b strings.Builder
+	b.WriteString("digraph code_to_graph {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontsize=10];\n")
+	b.WriteString("  edge [fontsize=9];\n")
+
+	byPackage := make(map[string][]Entity)
+	for _, e := range result.Entities {
+		byPackage[e.Package] = append(byPackage[e.Package], e)
+	}
+
+	var packages []string
+	for pkg := range byPackage {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		fmt.Fprintf(&b, "  subgraph %q {\n", "cluster_"+dotSafeID(pkg))
+		fmt.Fprintf(&b, "    label=%q;\n", pkg)
+		for _, e := range byPackage[pkg] {
+			fmt.Fprintf(&b, "    %q [label=%q shape=%s];\n", e.ID, e.Name, dotShape(e.Type))
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, r := range result.Relationships {
+		if r.SourceID == "" || r.TargetID == "" {
+			continue // unresolved edges have nothing to draw an arrow between
+		}
+		fmt.Fprintf(&b, "  %q -> %q [label=%q style=%s];\n", r.SourceID, r.TargetID, r.Type, dotStyle(r.Type))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotSafeID(s string) string {
+	return strings.NewReplacer("/", "_", ".", "_", "-", "_").Replace(s)
+}
+
+// GraphML node/edge shapes, using the standard xml package since GraphML is
+// just typed XML attribute data.
+type graphmlKey struct {
+	XMLName xml.Name `xml:"key"`
+	ID      string   `xml:"id,attr"`
+	For     string   `xml:"for,attr"`
+	Name    string   `xml:"attr.name,attr"`
+	Type    string   `xml:"attr.type,attr"`
+}
+
+type graphmlData struct {
+	XMLName xml.Name `xml:"data"`
+	Key     string   `xml:"key,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	XMLName xml.Name      `xml:"node"`
+	ID      string        `xml:"id,attr"`
+	Data    []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	XMLName xml.Name      `xml:"edge"`
+	Source  string        `xml:"source,attr"`
+	Target  string        `xml:"target,attr"`
+	Data    []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	XMLName     xml.Name      `xml:"graph"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+// RenderGraphML renders result as GraphML with typed node/edge attributes
+// (name, kind, package, file for nodes; type for edges) so it opens cleanly
+// in yEd or Gephi.
+func RenderGraphML(w io.Writer, result AnalysisResult) error {
+	doc := graphmlDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "n_name", For: "node", Name: "name", Type: "string"},
+			{ID: "n_kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "n_package", For: "node", Name: "package", Type: "string"},
+			{ID: "n_file", For: "node", Name: "file", Type: "string"},
+			{ID: "e_type", For: "edge", Name: "type", Type: "string"},
+		},
+		Graph: graphmlGraph{EdgeDefault: "directed"},
+	}
+
+	for _, e := range result.Entities {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphmlNode{
+			ID: e.ID,
+			Data: []graphmlData{
+				{Key: "n_name", Value: e.Name},
+				{Key: "n_kind", Value: e.Type},
+				{Key: "n_package", Value: e.Package},
+				{Key: "n_file", Value: e.File},
+			},
+		})
+	}
+
+	for _, r := range result.Relationships {
+		if r.SourceID == "" || r.TargetID == "" {
+			continue
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			Source: r.SourceID,
+			Target: r.TargetID,
+			Data:   []graphmlData{{Key: "e_type", Value: r.Type}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// Cytoscape.js element JSON: {elements: {nodes: [...], edges: [...]}}.
+type cytoscapeNodeData struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Kind    string `json:"kind"`
+	Package string `json:"package"`
+}
+
+type cytoscapeNode struct {
+	Data cytoscapeNodeData `json:"data"`
+}
+
+type cytoscapeEdgeData struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type cytoscapeEdge struct {
+	Data cytoscapeEdgeData `json:"data"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeNode `json:"nodes"`
+	Edges []cytoscapeEdge `json:"edges"`
+}
+
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+}
+
+func toCytoscape(result AnalysisResult) cytoscapeGraph {
+	var g cytoscapeGraph
+	for _, e := range result.Entities {
+		g.Elements.Nodes = append(g.Elements.Nodes, cytoscapeNode{Data: cytoscapeNodeData{
+			ID:      e.ID,
+			Label:   e.Name,
+			Kind:    e.Type,
+			Package: e.Package,
+		}})
+	}
+	for _, r := range result.Relationships {
+		if r.SourceID == "" || r.TargetID == "" {
+			continue
+		}
+		g.Elements.Edges = append(g.Elements.Edges, cytoscapeEdge{Data: cytoscapeEdgeData{
+			ID:     r.ID,
+			Source: r.SourceID,
+			Target: r.TargetID,
+			Type:   r.Type,
+		}})
+	}
+	return g
+}
+
+// FocusSubgraph restricts result to the induced subgraph within depth
+// relationship hops of every entity whose name matches the glob pattern
+// (path.Match syntax, e.g. "Handle*").
+func FocusSubgraph(result AnalysisResult, glob string, depth int) (AnalysisResult, error) {
+	adjacency := make(map[string][]string)
+	for _, r := range result.Relationships {
+		if r.SourceID == "" || r.TargetID == "" {
+			continue
+		}
+		adjacency[r.SourceID] = append(adjacency[r.SourceID], r.TargetID)
+		adjacency[r.TargetID] = append(adjacency[r.TargetID], r.SourceID)
+	}
+
+	keep := make(map[string]bool)
+	frontier := make(map[string]bool)
+	for _, e := range result.Entities {
+		matched, err := filepath.Match(glob, e.Name)
+		if err != nil {
+			return AnalysisResult{}, fmt.Errorf("invalid -focus glob %q: %w", glob, err)
+		}
+		if matched {
+			keep[e.ID] = true
+			frontier[e.ID] = true
+		}
+	}
+
+	for hop := 0; hop < depth; hop++ {
+		next := make(map[string]bool)
+		for id := range frontier {
+			for _, neighbor := range adjacency[id] {
+				if !keep[neighbor] {
+					keep[neighbor] = true
+					next[neighbor] = true
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		frontier = next
+	}
+
+	var entities []Entity
+	for _, e := range result.Entities {
+		if keep[e.ID] {
+			entities = append(entities, e)
+		}
+	}
+
+	var relationships []Relationship
+	for _, r := range result.Relationships {
+		if keep[r.SourceID] && keep[r.TargetID] {
+			relationships = append(relationships, r)
+		}
+	}
+
+	result.Entities = entities
+	result.Relationships = relationships
+	result.Stats = focusedStats(entities, relationships)
+	return result, nil
+}
+
+// focusedStats recomputes AnalysisStats for a filtered entity/relationship
+// set, the same way calculateStats does, except TotalPackages is counted
+// from the surviving entities rather than the original *packages.Package
+// list (which FocusSubgraph, operating purely on the already-extracted
+// graph, no longer has access to).
+func focusedStats(entities []Entity, relationships []Relationship) AnalysisStats {
+	stats := AnalysisStats{
+		TotalEntities:       len(entities),
+		TotalRelationships:  len(relationships),
+		EntitiesByType:      make(map[string]int),
+		RelationshipsByType: make(map[string]int),
+	}
+
+	fileSet := make(map[string]bool)
+	packageSet := make(map[string]bool)
+	for _, e := range entities {
+		fileSet[e.File] = true
+		packageSet[e.Package] = true
+		stats.EntitiesByType[e.Type]++
+	}
+	stats.TotalFiles = len(fileSet)
+	stats.TotalPackages = len(packageSet)
+
+	for _, r := range relationships {
+		stats.RelationshipsByType[r.Type]++
+	}
+
+	return stats
+}
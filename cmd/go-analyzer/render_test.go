@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func sampleResult() AnalysisResult {
+	return AnalysisResult{
+		Success:  true,
+		Language: "go",
+		Entities: []Entity{
+			{ID: "e1", Name: "Caller", Type: "function", Package: "p", File: "p.go"},
+			{ID: "e2", Name: "Callee", Type: "function", Package: "p", File: "p.go"},
+		},
+		Relationships: []Relationship{
+			{ID: "r1", SourceID: "e1", SourceName: "Caller", TargetID: "e2", TargetName: "Callee", Type: "calls"},
+			{ID: "r2", SourceID: "e1", SourceName: "Caller", TargetID: "", TargetName: "unresolved", Type: "calls"},
+		},
+	}
+}
+
+// TestRenderJSONRoundTrips covers Render's default/"json" branch.
+func TestRenderJSONRoundTrips(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, sampleResult(), "json"); err != nil {
+		t.Fatalf("Render(json): %v", err)
+	}
+	var got AnalysisResult
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(got.Entities) != 2 {
+		t.Errorf("expected 2 entities round-tripped, got %d", len(got.Entities))
+	}
+}
+
+// TestRenderUnknownFormatErrors covers Render's default case.
+func TestRenderUnknownFormatErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, sampleResult(), "yaml"); err == nil {
+		t.Fatal("expected an error for an unknown format, got nil")
+	}
+}
+
+// TestRenderDOTGroupsByPackageAndDropsUnresolvedEdges covers RenderDOT: a
+// package cluster per entity package, and relationships with no TargetID
+// (unresolved calls) must not produce a dangling arrow.
+func TestRenderDOTGroupsByPackageAndDropsUnresolvedEdges(t *testing.T) {
+	dot := RenderDOT(sampleResult())
+
+	if !strings.Contains(dot, `subgraph "cluster_p"`) {
+		t.Errorf("expected a cluster_p subgraph, got:\n%s", dot)
+	}
+	if !strings.Contains(dot, `"e1" -> "e2"`) {
+		t.Errorf("expected an e1 -> e2 edge, got:\n%s", dot)
+	}
+	if strings.Contains(dot, `-> ""`) {
+		t.Errorf("unresolved relationship (empty TargetID) should not be rendered, got:\n%s", dot)
+	}
+}
+
+// TestRenderGraphMLWritesNodesAndEdges covers RenderGraphML's XML shape and
+// confirms it also drops unresolved edges.
+func TestRenderGraphMLWritesNodesAndEdges(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderGraphML(&buf, sampleResult()); err != nil {
+		t.Fatalf("RenderGraphML: %v", err)
+	}
+
+	var doc graphmlDocument
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("unmarshal graphml: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(doc.Graph.Nodes))
+	}
+	if len(doc.Graph.Edges) != 1 {
+		t.Errorf("expected 1 edge (unresolved edge dropped), got %d", len(doc.Graph.Edges))
+	}
+}
+
+// TestToCytoscapeDropsUnresolvedEdges covers toCytoscape's node/edge mapping.
+func TestToCytoscapeDropsUnresolvedEdges(t *testing.T) {
+	g := toCytoscape(sampleResult())
+	if len(g.Elements.Nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(g.Elements.Nodes))
+	}
+	if len(g.Elements.Edges) != 1 {
+		t.Errorf("expected 1 edge, got %d", len(g.Elements.Edges))
+	}
+	if g.Elements.Edges[0].Data.Source != "e1" || g.Elements.Edges[0].Data.Target != "e2" {
+		t.Errorf("unexpected edge: %+v", g.Elements.Edges[0])
+	}
+}
+
+// TestFocusSubgraphKeepsMatchesAndNeighborsWithinDepth covers the glob match
+// plus hop-limited BFS expansion.
+func TestFocusSubgraphKeepsMatchesAndNeighborsWithinDepth(t *testing.T) {
+	result := AnalysisResult{
+		Entities: []Entity{
+			{ID: "a", Name: "HandleRequest", Package: "p", File: "p.go"},
+			{ID: "b", Name: "validate", Package: "p", File: "p.go"},
+			{ID: "c", Name: "farAway", Package: "p", File: "p.go"},
+		},
+		Relationships: []Relationship{
+			{ID: "r1", SourceID: "a", TargetID: "b", Type: "calls"},
+			{ID: "r2", SourceID: "b", TargetID: "c", Type: "calls"},
+		},
+	}
+
+	focused, err := FocusSubgraph(result, "Handle*", 1)
+	if err != nil {
+		t.Fatalf("FocusSubgraph: %v", err)
+	}
+
+	ids := make(map[string]bool, len(focused.Entities))
+	for _, e := range focused.Entities {
+		ids[e.ID] = true
+	}
+	if !ids["a"] || !ids["b"] {
+		t.Errorf("expected a and b within 1 hop of the Handle* match, got %+v", ids)
+	}
+	if ids["c"] {
+		t.Errorf("c is 2 hops away and depth is 1, should not be kept: %+v", ids)
+	}
+	if focused.Stats.TotalEntities != len(focused.Entities) {
+		t.Errorf("Stats.TotalEntities = %d, want %d", focused.Stats.TotalEntities, len(focused.Entities))
+	}
+}
+
+// TestFocusSubgraphInvalidGlobErrors covers the path.Match error wrapping.
+func TestFocusSubgraphInvalidGlobErrors(t *testing.T) {
+	if _, err := FocusSubgraph(sampleResult(), "[", 1); err == nil {
+		t.Fatal("expected an error for an invalid glob pattern, got nil")
+	}
+}
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SSAPhi is a phi-node placed at the start of a block for one source-level
+// variable, selecting its current SSA name depending on which predecessor
+// control arrived from.
+type SSAPhi struct {
+	Block    int              `json:"block"`
+	Var      string           `json:"var"`
+	Result   string           `json:"result"`
+	Operands []SSAPhiOperand  `json:"operands"`
+}
+
+// SSAPhiOperand is one incoming edge of a phi-node.
+type SSAPhiOperand struct {
+	PredBlock int    `json:"pred_block"`
+	Value     string `json:"value"`
+}
+
+// SSADef records a single SSA definition of a source-level variable.
+type SSADef struct {
+	Var     string `json:"var"`
+	SSAName string `json:"ssa_name"`
+	Block   int    `json:"block"`
+	Line    int    `json:"line"`
+}
+
+// SSAUse records a single use of an SSA name (rewritten to the definition
+// reaching that point).
+type SSAUse struct {
+	Var     string `json:"var"`
+	SSAName string `json:"ssa_name"`
+	Block   int    `json:"block"`
+	Line    int    `json:"line"`
+}
+
+// SSABlockInfo is the per-block slice of the pruned SSA form.
+type SSABlockInfo struct {
+	Block int      `json:"block"`
+	Phis  []SSAPhi `json:"phis,omitempty"`
+	Defs  []SSADef `json:"defs,omitempty"`
+	Uses  []SSAUse `json:"uses,omitempty"`
+}
+
+// SSAResult is the pruned SSA form of a function, built on top of its CFG.
+type SSAResult struct {
+	Blocks             []SSABlockInfo   `json:"blocks"`
+	IDom               []int            `json:"idom"`
+	DominanceFrontier  map[int][]int    `json:"dominance_frontier,omitempty"`
+	EscapedVars        []string         `json:"escaped_vars,omitempty"`
+	SanityChecked      bool             `json:"sanity_checked"`
+	SanityMismatch     bool             `json:"sanity_mismatch,omitempty"`
+}
+
+// SSABuilder converts a function's CFG into pruned SSA form: dominator tree
+// (Lengauer-Tarjan), dominance frontier (Cytron et al.), phi placement at
+// the iterated dominance frontier of each non-escaping local, and renaming
+// by a dominator-tree DFS.
+type SSABuilder struct {
+	blocks []*cfgBlock
+	entry  int
+	pkg    *packages.Package
+	sanity bool
+}
+
+// NewSSABuilder creates an SSA builder for the given block graph, rooted at
+// entry (the cfgBuilder's entryBlock.id - the exit block is always created
+// first, so the entry is never reliably at index 0, and a block's kind can
+// be overwritten in place as the builder walks the function body, so it
+// can't be recovered by scanning for kind == "entry" either). When sanity
+// is true, the Lengauer-Tarjan dominator tree is cross-checked against a
+// naive iterative dataflow computation.
+func NewSSABuilder(blocks []*cfgBlock, entry int, pkg *packages.Package, sanity bool) *SSABuilder {
+	return &SSABuilder{blocks: blocks, entry: entry, pkg: pkg, sanity: sanity}
+}
+
+// Build runs the four SSA construction stages and returns the result.
+func (sb *SSABuilder) Build() SSAResult {
+	n := len(sb.blocks)
+	if n == 0 {
+		return SSAResult{Blocks: []SSABlockInfo{}, IDom: []int{}}
+	}
+
+	preds, succs := sb.adjacency()
+	entry := sb.entry
+	idom := computeDominatorsLT(n, preds, succs, entry)
+
+	result := SSAResult{IDom: idom}
+	if sb.sanity {
+		naive := computeDominatorsNaive(n, preds, succs, entry)
+		result.SanityChecked = true
+		for i := range idom {
+			if idom[i] != naive[i] {
+				result.SanityMismatch = true
+				break
+			}
+		}
+	}
+
+	domFrontier := computeDominanceFrontier(n, preds, idom)
+	result.DominanceFrontier = domFrontier
+
+	defs, escaped := sb.collectLocalDefs()
+	result.EscapedVars = escaped
+
+	phiBlocks := placePhis(n, domFrontier, defs)
+	result.Blocks = sb.rename(idom, defs, phiBlocks)
+
+	return result
+}
+
+func (sb *SSABuilder) adjacency() (preds, succs [][]int) {
+	n := len(sb.blocks)
+	preds = make([][]int, n)
+	succs = make([][]int, n)
+	for _, b := range sb.blocks {
+		for _, s := range b.succs {
+			succs[b.id] = append(succs[b.id], s.id)
+		}
+		for _, p := range b.preds {
+			preds[b.id] = append(preds[b.id], p.id)
+		}
+	}
+	return preds, succs
+}
+
+// computeDominatorsLT computes immediate dominators using the simple
+// Lengauer-Tarjan algorithm: a DFS to assign semidominator numbers, then a
+// reverse-DFS-order pass using a link/eval structure with path compression.
+func computeDominatorsLT(n int, preds, succs [][]int, entry int) []int {
+	const undefined = -1
+
+	dfnum := make([]int, n)
+	parent := make([]int, n)
+	vertex := make([]int, 0, n)
+	for i := range dfnum {
+		dfnum[i] = undefined
+	}
+
+	var dfs func(v int)
+	dfs = func(v int) {
+		dfnum[v] = len(vertex)
+		vertex = append(vertex, v)
+		for _, w := range succs[v] {
+			if dfnum[w] == undefined {
+				parent[w] = v
+				dfs(w)
+			}
+		}
+	}
+	dfs(entry)
+
+	ancestor := make([]int, n)
+	label := make([]int, n)
+	semi := make([]int, n)
+	samedom := make([]int, n)
+	idom := make([]int, n)
+	bucket := make([][]int, n)
+	for i := 0; i < n; i++ {
+		ancestor[i] = undefined
+		label[i] = i
+		semi[i] = i
+		samedom[i] = undefined
+		idom[i] = undefined
+	}
+
+	var compress func(v int)
+	compress = func(v int) {
+		if ancestor[ancestor[v]] != undefined {
+			compress(ancestor[v])
+			if dfnum[semi[label[ancestor[v]]]] < dfnum[semi[label[v]]] {
+				label[v] = label[ancestor[v]]
+			}
+			ancestor[v] = ancestor[ancestor[v]]
+		}
+	}
+	eval := func(v int) int {
+		if ancestor[v] == undefined {
+			return v
+		}
+		compress(v)
+		return label[v]
+	}
+	link := func(v, w int) {
+		ancestor[w] = v
+	}
+
+	for i := len(vertex) - 1; i >= 1; i-- {
+		w := vertex[i]
+		for _, v := range preds[w] {
+			if dfnum[v] == undefined {
+				continue // unreachable predecessor
+			}
+			u := eval(v)
+			if dfnum[semi[u]] < dfnum[semi[w]] {
+				semi[w] = semi[u]
+			}
+		}
+		bucket[semi[w]] = append(bucket[semi[w]], w)
+		link(parent[w], w)
+
+		for _, v := range bucket[parent[w]] {
+			u := eval(v)
+			if dfnum[semi[u]] < dfnum[semi[v]] {
+				samedom[v] = u
+			} else {
+				idom[v] = parent[w]
+			}
+		}
+		bucket[parent[w]] = nil
+	}
+
+	for i := 1; i < len(vertex); i++ {
+		w := vertex[i]
+		if samedom[w] != undefined {
+			idom[w] = idom[samedom[w]]
+		}
+	}
+	idom[entry] = entry
+
+	return idom
+}
+
+// computeDominatorsNaive computes immediate dominators by iterating
+// "intersect predecessors' dominator sets to fixpoint" over a reverse
+// postorder of the graph, used to sanity-check the Lengauer-Tarjan result.
+func computeDominatorsNaive(n int, preds, succs [][]int, entry int) []int {
+	order := reversePostorder(n, succs, entry)
+	rpoNum := make([]int, n)
+	for i, v := range order {
+		rpoNum[v] = i
+	}
+
+	const undefined = -1
+	idom := make([]int, n)
+	for i := range idom {
+		idom[i] = undefined
+	}
+	idom[entry] = entry
+
+	intersect := func(a, b int) int {
+		for a != b {
+			for rpoNum[a] > rpoNum[b] {
+				a = idom[a]
+			}
+			for rpoNum[b] > rpoNum[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	changed := true
+	for changed {
+		changed = false
+		for _, v := range order {
+			if v == entry {
+				continue
+			}
+			newIdom := undefined
+			for _, p := range preds[v] {
+				if idom[p] == undefined {
+					continue
+				}
+				if newIdom == undefined {
+					newIdom = p
+				} else {
+					newIdom = intersect(newIdom, p)
+				}
+			}
+			if newIdom != undefined && idom[v] != newIdom {
+				idom[v] = newIdom
+				changed = true
+			}
+		}
+	}
+	return idom
+}
+
+func reversePostorder(n int, succs [][]int, entry int) []int {
+	visited := make([]bool, n)
+	var post []int
+	var visit func(v int)
+	visit = func(v int) {
+		visited[v] = true
+		for _, w := range succs[v] {
+			if !visited[w] {
+				visit(w)
+			}
+		}
+		post = append(post, v)
+	}
+	visit(entry)
+
+	order := make([]int, 0, len(post))
+	for i := len(post) - 1; i >= 0; i-- {
+		order = append(order, post[i])
+	}
+	return order
+}
+
+// computeDominanceFrontier implements the Cytron et al. algorithm: for every
+// block b with >=2 predecessors, walk each predecessor p upward via idom
+// until reaching idom[b], adding b to DF[p] at each step.
+func computeDominanceFrontier(n int, preds [][]int, idom []int) map[int][]int {
+	df := make(map[int][]int)
+	for b := 0; b < n; b++ {
+		if len(preds[b]) < 2 {
+			continue
+		}
+		for _, p := range preds[b] {
+			if idom[p] == -1 {
+				continue // unreachable predecessor
+			}
+			runner := p
+			for runner != idom[b] {
+				df[runner] = appendUnique(df[runner], b)
+				runner = idom[runner]
+			}
+		}
+	}
+	return df
+}
+
+func appendUnique(s []int, v int) []int {
+	for _, x := range s {
+		if x == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+// localDef is one assignment to a source-level local variable.
+type localDef struct {
+	name  string
+	block int
+	line  int
+}
+
+// collectLocalDefs walks every block's statements and the function's
+// parameter list to find defining occurrences of local identifiers,
+// skipping any variable that escapes (address taken, or captured by a
+// nested closure).
+func (sb *SSABuilder) collectLocalDefs() (map[string][]localDef, []string) {
+	escapes := make(map[string]bool)
+	for _, b := range sb.blocks {
+		for _, s := range b.stmts {
+			markEscapes(s, escapes)
+		}
+	}
+
+	defs := make(map[string][]localDef)
+	for _, b := range sb.blocks {
+		for _, s := range b.stmts {
+			for _, name := range definedNames(s) {
+				if escapes[name] {
+					continue
+				}
+				defs[name] = append(defs[name], localDef{name: name, block: b.id, line: b.line})
+			}
+		}
+	}
+
+	var escaped []string
+	for name := range escapes {
+		escaped = append(escaped, name)
+	}
+	return defs, escaped
+}
+
+// markEscapes flags identifiers whose address is taken, or that are
+// referenced inside a nested function literal (captured by closure); such
+// variables are excluded from SSA renaming.
+func markEscapes(stmt ast.Stmt, escapes map[string]bool) {
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.UnaryExpr:
+			if e.Op == token.AND {
+				if id, ok := e.X.(*ast.Ident); ok {
+					escapes[id.Name] = true
+				}
+			}
+		case *ast.FuncLit:
+			ast.Inspect(e.Body, func(m ast.Node) bool {
+				if id, ok := m.(*ast.Ident); ok {
+					escapes[id.Name] = true
+				}
+				return true
+			})
+		}
+		return true
+	})
+}
+
+// definedNames returns the names defined (assigned or declared) by stmt at
+// the statement level, ignoring anything nested inside it (those are
+// visited separately as their own statements in their own blocks).
+func definedNames(stmt ast.Stmt) []string {
+	var names []string
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range s.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" {
+				names = append(names, id.Name)
+			}
+		}
+	case *ast.DeclStmt:
+		genDecl, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			break
+		}
+		for _, spec := range genDecl.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				for _, id := range valueSpec.Names {
+					if id.Name != "_" {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// placePhis computes, for every non-escaping local variable, the iterated
+// dominance frontier of its defining blocks via the standard worklist
+// algorithm, and returns the set of variables that need a phi at each block.
+func placePhis(n int, df map[int][]int, defs map[string][]localDef) map[int][]string {
+	phiBlocks := make(map[int][]string)
+
+	for name, occurrences := range defs {
+		hasPhi := make(map[int]bool)
+		worklist := make([]int, 0, len(occurrences))
+		seenDef := make(map[int]bool)
+		for _, d := range occurrences {
+			if !seenDef[d.block] {
+				seenDef[d.block] = true
+				worklist = append(worklist, d.block)
+			}
+		}
+
+		for len(worklist) > 0 {
+			b := worklist[len(worklist)-1]
+			worklist = worklist[:len(worklist)-1]
+			for _, f := range df[b] {
+				if !hasPhi[f] {
+					hasPhi[f] = true
+					phiBlocks[f] = append(phiBlocks[f], name)
+					if !seenDef[f] {
+						seenDef[f] = true
+						worklist = append(worklist, f)
+					}
+				}
+			}
+		}
+	}
+
+	return phiBlocks
+}
+
+// ssaRenamer renames definitions/uses by a DFS over the dominator tree,
+// keeping a per-variable stack of the current SSA name.
+type ssaRenamer struct {
+	blocks    []*cfgBlock
+	idom      []int
+	children  [][]int
+	phiBlocks map[int][]string
+	counters  map[string]int
+	stacks    map[string][]string
+	out       []SSABlockInfo
+}
+
+func (sb *SSABuilder) rename(idom []int, defs map[string][]localDef, phiBlocks map[int][]string) []SSABlockInfo {
+	n := len(sb.blocks)
+	children := make([][]int, n)
+	for v := 0; v < n; v++ {
+		// idom[v] is -1 for a block the dominator pass never reached (e.g.
+		// genuinely unreachable code). Skip it rather than indexing
+		// children with a negative id.
+		if v != idom[v] && idom[v] >= 0 {
+			children[idom[v]] = append(children[idom[v]], v)
+		}
+	}
+
+	byID := make(map[int]*cfgBlock, n)
+	for _, b := range sb.blocks {
+		byID[b.id] = b
+	}
+
+	r := &ssaRenamer{
+		blocks:    sb.blocks,
+		idom:      idom,
+		children:  children,
+		phiBlocks: phiBlocks,
+		counters:  make(map[string]int),
+		stacks:    make(map[string][]string),
+		out:       make([]SSABlockInfo, n),
+	}
+	for i := 0; i < n; i++ {
+		r.out[i] = SSABlockInfo{Block: i}
+	}
+
+	r.visit(sb.entry, byID)
+	return r.out
+}
+
+func (r *ssaRenamer) fresh(name string) string {
+	r.counters[name]++
+	ssaName := fmt.Sprintf("%s#%d", name, r.counters[name])
+	r.stacks[name] = append(r.stacks[name], ssaName)
+	return ssaName
+}
+
+func (r *ssaRenamer) current(name string) string {
+	stack := r.stacks[name]
+	if len(stack) == 0 {
+		return name + "#0" // undefined on entry (e.g. a parameter or zero value)
+	}
+	return stack[len(stack)-1]
+}
+
+func (r *ssaRenamer) visit(blockID int, byID map[int]*cfgBlock) {
+	b := byID[blockID]
+	popCounts := make(map[string]int)
+
+	// Phi destinations are pushed first so uses later in the block see them.
+	for _, name := range r.phiBlocks[blockID] {
+		ssaName := r.fresh(name)
+		popCounts[name]++
+		r.out[blockID].Phis = append(r.out[blockID].Phis, SSAPhi{
+			Block:  blockID,
+			Var:    name,
+			Result: ssaName,
+		})
+	}
+
+	for _, stmt := range b.stmts {
+		for _, name := range usedNames(stmt, r.phiBlocks[blockID]) {
+			r.out[blockID].Uses = append(r.out[blockID].Uses, SSAUse{
+				Var:     name,
+				SSAName: r.current(name),
+				Block:   blockID,
+				Line:    b.line,
+			})
+		}
+		for _, name := range definedNames(stmt) {
+			ssaName := r.fresh(name)
+			popCounts[name]++
+			r.out[blockID].Defs = append(r.out[blockID].Defs, SSADef{
+				Var:     name,
+				SSAName: ssaName,
+				Block:   blockID,
+				Line:    b.line,
+			})
+		}
+	}
+
+	// Propagate current names into phi operands of successor blocks.
+	for _, succ := range b.succs {
+		for i, name := range r.phiBlocks[succ.id] {
+			r.out[succ.id].Phis[i].Operands = append(r.out[succ.id].Phis[i].Operands, SSAPhiOperand{
+				PredBlock: blockID,
+				Value:     r.current(name),
+			})
+		}
+	}
+
+	for _, child := range r.children[blockID] {
+		r.visit(child, byID)
+	}
+
+	for name, count := range popCounts {
+		stack := r.stacks[name]
+		r.stacks[name] = stack[:len(stack)-count]
+	}
+}
+
+// usedNames returns the locally-defined variable names read by stmt. The
+// exact ident nodes that are plain assignment/declaration targets are
+// excluded by identity (not by name), so `x = x + 1` still counts the RHS
+// `x` as a use while the LHS `x` is recorded as a def.
+func usedNames(stmt ast.Stmt, _ []string) []string {
+	excluded := make(map[*ast.Ident]bool)
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range s.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok {
+				excluded[id] = true
+			}
+		}
+	case *ast.DeclStmt:
+		if genDecl, ok := s.Decl.(*ast.GenDecl); ok {
+			for _, spec := range genDecl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					for _, id := range valueSpec.Names {
+						excluded[id] = true
+					}
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id.Name == "_" || excluded[id] {
+			return true
+		}
+		if seen[id.Name] {
+			return true
+		}
+		seen[id.Name] = true
+		names = append(names, id.Name)
+		return true
+	})
+	return names
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// parseFunc parses src (a full file, package included) and returns the
+// *ast.FuncDecl for the function named name, along with the FileSet it was
+// parsed with.
+func parseFunc(t *testing.T, src, name string) (*ast.FuncDecl, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name {
+			return fn, fset
+		}
+	}
+	t.Fatalf("function %s not found", name)
+	return nil, nil
+}
+
+// buildSSA builds the CFG and pruned SSA for fn, cross-checked against the
+// naive dominator computation.
+func buildSSA(t *testing.T, fn *ast.FuncDecl, fset *token.FileSet) (*cfgBuilder, SSAResult) {
+	t.Helper()
+	builder := &cfgBuilder{fileSet: fset, fn: fn, labels: make(map[string]*cfgBlock)}
+	builder.build()
+	result := NewSSABuilder(builder.blocks, builder.entryBlock.id, nil, true).Build()
+	if result.SanityMismatch {
+		t.Fatalf("Lengauer-Tarjan dominator tree disagrees with the naive computation: idom=%v", result.IDom)
+	}
+	return builder, result
+}
+
+// This is a regression test for a bug where build() creates the exit block
+// before the entry block, so the entry is never reliably at index 0: the
+// dominator computation and the SSA renaming DFS both used to be seeded
+// with a hardcoded 0 and so never reached any real block, leaving every
+// idom[v] == -1 and panicking in rename's children[idom[v]] construction.
+func TestComputeDominatorsLTSeedsFromRealEntry(t *testing.T) {
+	src := `package p
+
+func F(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 2
+}
+`
+	fn, fset := parseFunc(t, src, "F")
+	builder, result := buildSSA(t, fn, fset)
+
+	if builder.entryBlock.id == builder.exitBlock.id {
+		t.Fatalf("entry and exit block should be distinct, both have id %d", builder.entryBlock.id)
+	}
+	for v, d := range result.IDom {
+		if d < 0 {
+			t.Errorf("idom[%d] = %d, want every block dominated (entry is id %d)", v, d, builder.entryBlock.id)
+		}
+	}
+}
+
+// TestRenameSkipsUnreachedBlocks is a regression test for rename's
+// children[idom[v]] construction panicking with "index out of range [-1]"
+// when a block (e.g. dead code after an unconditional return) was never
+// reached by the dominator pass. Build should complete without panicking
+// and should still flag the dead block as unreachable.
+func TestRenameSkipsUnreachedBlocks(t *testing.T) {
+	src := `package p
+
+func G(x int) int {
+	if x > 0 {
+		return 1
+	} else {
+		return 2
+	}
+	return 3
+}
+`
+	fn, fset := parseFunc(t, src, "G")
+	builder := &cfgBuilder{fileSet: fset, fn: fn, labels: make(map[string]*cfgBlock)}
+	builder.build()
+	unreachable := builder.unreachableBlocks()
+	if len(unreachable) == 0 {
+		t.Fatal("expected the dead `return 3` block to be reported unreachable")
+	}
+
+	// Must not panic.
+	result := NewSSABuilder(builder.blocks, builder.entryBlock.id, nil, false).Build()
+	for _, id := range unreachable {
+		if result.IDom[id] >= 0 {
+			t.Errorf("idom[%d] = %d for an unreachable block, want -1 (unresolved)", id, result.IDom[id])
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// UnusedFlags mirrors the -detect-unused/-unused-graph flags.
+type UnusedFlags struct {
+	Enabled   bool
+	GraphFile string
+}
+
+// UnusedAnalysis is the result of DetectUnused: the full dependency graph
+// (handed to -unused-graph for debugging) plus the roots it swept from and
+// the entity IDs that were never reached.
+type UnusedAnalysis struct {
+	Roots  []string
+	Graph  map[string][]string
+	Unused []string
+}
+
+// DetectUnused performs a staticcheck "unused"-style mark-and-sweep over the
+// extracted entity graph. Roots are main.main, every init function, every
+// exported identifier of a non-main package, and a conservative set of
+// go:linkname/go:embed/reflection heuristics. From the roots, reachability
+// follows resolved "calls" edges, "embeds" edges, and a synthesized
+// interface -> implementing-method edge: once an interface is reachable,
+// every concrete type's method satisfying it is treated as reachable too,
+// since any of them could be invoked through that interface.
+func DetectUnused(pkgs []*packages.Package, fset *token.FileSet, entities []Entity, relationships []Relationship) UnusedAnalysis {
+	byID := make(map[string]*Entity, len(entities))
+	for i := range entities {
+		byID[entities[i].ID] = &entities[i]
+	}
+
+	graph := buildUnusedDependencyGraph(byID, relationships)
+	roots := unusedRoots(pkgs, fset, entities)
+	reachable := sweepReachable(graph, roots)
+
+	var unused []string
+	for _, e := range entities {
+		if !reachable[e.ID] {
+			unused = append(unused, e.ID)
+		}
+	}
+	sort.Strings(unused)
+
+	return UnusedAnalysis{Roots: roots, Graph: graph, Unused: unused}
+}
+
+// buildUnusedDependencyGraph turns the already-extracted relationships into
+// an adjacency list of "this entity keeps that entity alive" edges.
+func buildUnusedDependencyGraph(byID map[string]*Entity, relationships []Relationship) map[string][]string {
+	graph := make(map[string][]string)
+	addEdge := func(from, to string) {
+		if from == "" || to == "" || from == to {
+			return
+		}
+		graph[from] = append(graph[from], to)
+	}
+
+	for _, r := range relationships {
+		switch r.Type {
+		case "calls", "embeds":
+			addEdge(r.SourceID, r.TargetID)
+		case "implements":
+			iface, ifaceOK := byID[r.TargetID]
+			concrete, concreteOK := byID[r.SourceID]
+			if !ifaceOK || !concreteOK {
+				continue
+			}
+			for _, methodName := range iface.Methods {
+				for id, e := range byID {
+					if e.Type == "method" && e.Name == methodName && strings.Contains(e.ReceiverType, concrete.Name) {
+						addEdge(r.TargetID, id)
+					}
+				}
+			}
+		}
+	}
+
+	return graph
+}
+
+// sweepReachable walks graph breadth-first from roots and returns the set of
+// every entity ID it reached.
+func sweepReachable(graph map[string][]string, roots []string) map[string]bool {
+	reachable := make(map[string]bool, len(roots))
+	stack := append([]string(nil), roots...)
+	for _, id := range roots {
+		reachable[id] = true
+	}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range graph[id] {
+			if !reachable[next] {
+				reachable[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return reachable
+}
+
+// exportedEntityKinds are the entity types eligible to be an automatic root
+// by virtue of being an exported identifier of a non-main package.
+var exportedEntityKinds = map[string]bool{
+	"function":  true,
+	"method":    true,
+	"struct":    true,
+	"interface": true,
+	"type":      true,
+	"variable":  true,
+	"constant":  true,
+}
+
+// unusedRoots computes the mark-and-sweep roots: main.main, every init,
+// every exported identifier of a non-main package, and the go:linkname/
+// go:embed/reflect.TypeOf heuristics found by walking the AST.
+func unusedRoots(pkgs []*packages.Package, fset *token.FileSet, entities []Entity) []string {
+	var roots []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			roots = append(roots, id)
+		}
+	}
+
+	for _, e := range entities {
+		switch {
+		case e.Type == "function" && e.Name == "main" && e.Package == "main":
+			add(e.ID)
+		case e.Name == "init":
+			add(e.ID)
+		case e.Package != "main" && exportedEntityKinds[e.Type] && getVisibility(e.Name) == "public":
+			add(e.ID)
+		}
+	}
+
+	posIndex := newEntityByPosition(entities, fset)
+	for _, pkg := range pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			walkUnusedHeuristics(file, pkg, posIndex, add)
+		}
+	}
+
+	return roots
+}
+
+// walkUnusedHeuristics finds the go:linkname/go:embed/reflect.TypeOf/json
+// struct-tag roots described in the request: symbols only ever referenced
+// indirectly, which a pure call/embed graph would otherwise mark dead.
+func walkUnusedHeuristics(file *ast.File, pkg *packages.Package, posIndex *entityByPosition, add func(string)) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			if hasDirectiveComment(node.Doc, "go:linkname") {
+				if id, ok := posIndex.lookup(pkg.Types.Path(), node.Pos(), node.Name.Name); ok {
+					add(id)
+				}
+			}
+
+		case *ast.GenDecl:
+			if hasDirectiveComment(node.Doc, "go:embed") {
+				for _, spec := range node.Specs {
+					if vs, ok := spec.(*ast.ValueSpec); ok {
+						for _, name := range vs.Names {
+							if id, ok := posIndex.lookup(pkg.Types.Path(), name.Pos(), name.Name); ok {
+								add(id)
+							}
+						}
+					}
+				}
+			}
+			if node.Tok == token.TYPE {
+				for _, spec := range node.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					st, ok := ts.Type.(*ast.StructType)
+					if !ok || !structHasJSONTag(st) {
+						continue
+					}
+					if id, ok := posIndex.lookup(pkg.Types.Path(), ts.Pos(), ts.Name.Name); ok {
+						add(id)
+					}
+				}
+			}
+
+		case *ast.CallExpr:
+			if !isReflectTypeOfCall(node) || len(node.Args) != 1 || pkg.TypesInfo == nil {
+				break
+			}
+			if t := pkg.TypesInfo.TypeOf(node.Args[0]); t != nil {
+				if named, ok := t.(*types.Named); ok {
+					if obj := named.Obj(); obj.Pkg() != nil {
+						if id, ok := posIndex.lookup(obj.Pkg().Path(), obj.Pos(), obj.Name()); ok {
+							add(id)
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// hasDirectiveComment reports whether doc carries a line-comment directive
+// named directive (e.g. "go:linkname", "go:embed"). It scans the raw
+// CommentGroup.List rather than calling doc.Text(): Text() deliberately
+// strips directive-style comments ("//name:args", no space after //) from
+// its output, so matching against it can never find one.
+func hasDirectiveComment(doc *ast.CommentGroup, directive string) bool {
+	if doc == nil {
+		return false
+	}
+	prefix := "//" + directive
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReflectTypeOfCall reports whether call is (syntactically) reflect.TypeOf(...).
+func isReflectTypeOfCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "TypeOf" {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == "reflect"
+}
+
+// structHasJSONTag reports whether any field of st carries a `json:"..."` tag
+// - a strong signal that the whole struct crosses a serialization boundary
+// and should be treated as reachable even with no direct Go-level reference.
+func structHasJSONTag(st *ast.StructType) bool {
+	if st.Fields == nil {
+		return false
+	}
+	for _, field := range st.Fields.List {
+		if field.Tag != nil && strings.Contains(field.Tag.Value, "json:") {
+			return true
+		}
+	}
+	return false
+}
+
+// entityByPosition indexes every entity by its declaration position (the
+// same pkgPath:name:line key entityPositionIndex and typeEntityIndex use -
+// not a file path, since Entity.File is relative to repoPath while fset
+// positions are always absolute, and not the short package name, since two
+// different packages can share one), so AST-level heuristics can be joined
+// back to the Entity they describe.
+type entityByPosition struct {
+	fset  *token.FileSet
+	byKey map[string]string
+}
+
+func newEntityByPosition(entities []Entity, fset *token.FileSet) *entityByPosition {
+	idx := &entityByPosition{fset: fset, byKey: make(map[string]string, len(entities))}
+	for _, e := range entities {
+		idx.byKey[fmt.Sprintf("%s:%s:%d", e.PkgPath, e.Name, e.StartLine)] = e.ID
+	}
+	return idx
+}
+
+func (idx *entityByPosition) lookup(pkgPath string, pos token.Pos, name string) (string, bool) {
+	if pos == token.NoPos {
+		return "", false
+	}
+	p := idx.fset.Position(pos)
+	id, ok := idx.byKey[fmt.Sprintf("%s:%s:%d", pkgPath, name, p.Line)]
+	return id, ok
+}
+
+// unusedGraphToDOT renders an UnusedAnalysis's dependency graph as a
+// Graphviz DOT digraph, with root nodes doubly-outlined, for -unused-graph.
+func unusedGraphToDOT(analysis UnusedAnalysis, byID map[string]*Entity) string {
+	roots := make(map[string]bool, len(analysis.Roots))
+	for _, id := range analysis.Roots {
+		roots[id] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph unused_reachability {\n")
+	b.WriteString("  rankdir=LR;\n")
+
+	for id, e := range byID {
+		shape := "ellipse"
+		if roots[id] {
+			shape = "doublecircle"
+		}
+		fmt.Fprintf(&b, "  %q [label=%q shape=%s];\n", id, e.Name, shape)
+	}
+	for from, tos := range analysis.Graph {
+		for _, to := range tos {
+			fmt.Fprintf(&b, "  %q -> %q;\n", from, to)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// writeUnusedGraph writes analysis's reachability graph as Graphviz DOT to path.
+func writeUnusedGraph(path string, analysis UnusedAnalysis, entities []Entity) error {
+	byID := make(map[string]*Entity, len(entities))
+	for i := range entities {
+		byID[entities[i].ID] = &entities[i]
+	}
+	return os.WriteFile(path, []byte(unusedGraphToDOT(analysis, byID)), 0644)
+}
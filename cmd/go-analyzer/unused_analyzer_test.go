@@ -0,0 +1,129 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// entitiesForUnused builds a minimal []Entity covering every top-level
+// function/method and type declaration in pkgs, the way
+// extractEntitiesFromFile does: File relative to repoPath, Package/Name/
+// StartLine from the declaration's own position. Keeping File relative
+// while fset positions stay absolute is what regresses entityByPosition's
+// old file-path keying.
+func entitiesForUnused(t *testing.T, repoPath string, pkgs []*packages.Package, fset *token.FileSet) []Entity {
+	t.Helper()
+	var entities []Entity
+	n := 0
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n2 ast.Node) bool {
+				switch decl := n2.(type) {
+				case *ast.FuncDecl:
+					pos := fset.Position(decl.Pos())
+					relFile, err := filepath.Rel(repoPath, pos.Filename)
+					if err != nil {
+						t.Fatalf("filepath.Rel: %v", err)
+					}
+					n++
+					entities = append(entities, Entity{
+						ID:        decl.Name.Name,
+						Name:      decl.Name.Name,
+						Type:      "function",
+						Package:   pkg.Name,
+						PkgPath:   pkg.PkgPath,
+						File:      relFile,
+						StartLine: pos.Line,
+					})
+				case *ast.TypeSpec:
+					pos := fset.Position(decl.Pos())
+					relFile, err := filepath.Rel(repoPath, pos.Filename)
+					if err != nil {
+						t.Fatalf("filepath.Rel: %v", err)
+					}
+					n++
+					entities = append(entities, Entity{
+						ID:        decl.Name.Name,
+						Name:      decl.Name.Name,
+						Type:      "struct",
+						Package:   pkg.Name,
+						PkgPath:   pkg.PkgPath,
+						File:      relFile,
+						StartLine: pos.Line,
+					})
+				}
+				return true
+			})
+		}
+	}
+	return entities
+}
+
+// TestUnusedRootsFindsGoLinknameTarget covers the go:linkname heuristic end
+// to end: a function named only by a //go:linkname directive comment (with
+// no Go-level caller) must be rooted, while a genuinely dead function next
+// to it must not be.
+//
+// This is a regression test for two bugs found by driving the real CLI:
+// entityByPosition used to key on Entity.File, which analyzeGoRepository
+// always stores relative to repoPath while fset positions stay absolute -
+// so the lookup could never match outside of a test rigged to keep both
+// sides absolute. And the go:linkname/go:embed check compared against
+// CommentGroup.Text(), which strips directive-style comments ("//name:args")
+// from its output, so the match could never succeed even once the keys lined
+// up.
+func TestUnusedRootsFindsGoLinknameTarget(t *testing.T) {
+	repoPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repoPath, "go.mod"), []byte("module testpkg\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	src := `package main
+
+//go:linkname reallyUsed
+func reallyUsed() {}
+
+func deadCode() {}
+
+func main() {}
+`
+	if err := os.WriteFile(filepath.Join(repoPath, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("write p.go: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedDeps | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedSyntax,
+		Dir:  repoPath,
+		Fset: fset,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("packages.Load: %v", err)
+	}
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			t.Fatalf("package load error: %v", e)
+		}
+	}
+
+	entities := entitiesForUnused(t, repoPath, pkgs, fset)
+	analysis := DetectUnused(pkgs, fset, entities, nil)
+
+	unused := make(map[string]bool, len(analysis.Unused))
+	for _, id := range analysis.Unused {
+		unused[id] = true
+	}
+	if unused["reallyUsed"] {
+		t.Error("reallyUsed is rooted by its go:linkname comment, want it reachable")
+	}
+	if !unused["deadCode"] {
+		t.Error("deadCode has no caller and no heuristic root, want it unused")
+	}
+}